@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/aymanbagabas/go-udiff/myers"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/ruanbekker/devops-ai-cli/internal/redact"
+)
+
+const revisePrompt = `Rewrite this %s applying your optimization suggestions.%s
+Reply with the complete revised file in a single fenced code block and nothing
+else. Keep comments and the parts that need no change exactly as they are.
+
+%s`
+
+var (
+	diffAddStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	diffRemoveStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	diffHunkStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("14"))
+	diffHeaderStyle = lipgloss.NewStyle().Bold(true)
+)
+
+var codeBlockPattern = regexp.MustCompile("(?s)(`{3,})[^\\n]*\\n(.*?)\\n?(?:`{3,})")
+
+// reviseFile asks the model for the complete revised file, asking again
+// when it is not valid
+func reviseFile(apiHost, apiKey, model, content string, kind filetype.Kind, instruction string) (string, error) {
+	hint := ""
+	if h := optimizeHints[kind]; h != "" {
+		hint = " " + h
+	}
+	prompt := fmt.Sprintf(revisePrompt, kind, hint, content)
+	if instruction != "" {
+		prompt = instruction + "\n\n" + prompt
+	}
+
+	// Ask again with the errors when the revised file does not parse or,
+	// for Kubernetes manifests, does not match the schemas
+	return requestRevision(apiHost, apiKey, model, prompt, content, func(revised string) error {
+		return checkRevision(kind, revised)
+	})
+}
+
+// requestRevision sends a prompt asking for a complete revised file and
+// returns the file from the reply, asking again while check fails
+func requestRevision(apiHost, apiKey, model, prompt, content string, check func(revised string) error) (string, error) {
+	// Secrets must be written back even when redaction.restore is off, so
+	// the placeholders are restored from the redactor that saw the prompt.
+	// They go back before the check, as a placeholder such as
+	// [REDACTED_K8S_SECRET_DATA_1] is not a valid value in most files.
+	redactor := newRedactor()
+	response, err := sendRedactedQuery(apiHost, apiKey, model, redactor, []map[string]string{
+		{"role": "user", "content": prompt},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// A reply without a code block is an explanation rather than a file,
+	// so it is never written
+	revision := func(response string) (string, error) {
+		block, ok := codeBlock(response)
+		if !ok {
+			return "", fmt.Errorf("the reply has no fenced code block with the revised file")
+		}
+		return redactor.Restore(block), nil
+	}
+
+	response, err = revalidate(apiHost, apiKey, model, redactor, prompt, response, func(response string) error {
+		revised, err := revision(response)
+		if err != nil {
+			return err
+		}
+		return check(revised)
+	})
+	if err != nil {
+		return "", fmt.Errorf("the revised file is not valid: %w", err)
+	}
+
+	revised, err := revision(response)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(revised, redact.PlaceholderPrefix) {
+		return "", fmt.Errorf("the revised file still contains redacted placeholders")
+	}
+	if strings.HasSuffix(content, "\n") && !strings.HasSuffix(revised, "\n") {
+		revised += "\n"
+	}
+	return revised, nil
+}
+
+// extractCodeBlock returns the content of the first fenced code block, or
+// the whole response when there is none
+func extractCodeBlock(response string) string {
+	if block, ok := codeBlock(response); ok {
+		return block
+	}
+	return strings.TrimSpace(response)
+}
+
+// codeBlock returns the content of the first fenced code block and whether
+// there is one
+func codeBlock(response string) (string, bool) {
+	if m := codeBlockPattern.FindStringSubmatch(response); m != nil {
+		return m[2], true
+	}
+	return "", false
+}
+
+// unifiedDiff returns a git style unified diff between two versions of path
+func unifiedDiff(path, before, after string) string {
+	name := diffPath(path)
+	// Line based edits keep inserted blocks together in the diff
+	diff, err := udiff.ToUnified("a/"+name, "b/"+name, before, myers.ComputeEdits(before, after), 3)
+	if err != nil {
+		return udiff.Unified("a/"+name, "b/"+name, before, after)
+	}
+	return diff
+}
+
+// diffPath returns path relative to the working directory, so the diff
+// applies with patch -p1 or git apply. Paths outside it lose the leading
+// slash rather than ending up as a//tmp/file.
+func diffPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, abs); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return strings.TrimPrefix(filepath.ToSlash(abs), "/")
+}
+
+// colorizeDiff colours added, removed and hunk lines of a unified diff
+func colorizeDiff(diff string) string {
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+			lines[i] = diffHeaderStyle.Render(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = diffAddStyle.Render(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = diffRemoveStyle.Render(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = diffHunkStyle.Render(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// confirm asks a yes/no question, defaulting to no
+func confirm(question string, in io.Reader, out io.Writer) bool {
+	fmt.Fprintf(out, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// writeWithBackup writes content to path after copying the current file to
+// path.bak, keeping the file mode. It returns the backup path.
+func writeWithBackup(path, content string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	backup := path + ".bak"
+	if err := os.WriteFile(backup, original, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("writing backup: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), info.Mode().Perm()); err != nil {
+		return backup, err
+	}
+	return backup, nil
+}
+
+// reviewChanges shows the diff between the original and revised content,
+// writes it to patchPath when set and writes the revised file when apply is
+// set and the change is confirmed (or yes is set)
+func reviewChanges(path, original, revised, patchPath string, apply, yes bool, in io.Reader, out io.Writer) error {
+	diff := unifiedDiff(path, original, revised)
+	if diff == "" {
+		fmt.Fprintln(out, "✅ No changes suggested.")
+		return nil
+	}
+	fmt.Fprintln(out, colorizeDiff(diff))
+
+	if patchPath != "" {
+		if err := os.WriteFile(patchPath, []byte(diff), 0644); err != nil {
+			return fmt.Errorf("writing patch: %w", err)
+		}
+		fmt.Fprintf(out, "📄 Patch saved to %s\n", patchPath)
+	}
+
+	if !apply {
+		return nil
+	}
+	if !yes && !confirm(fmt.Sprintf("Apply these changes to %s?", path), in, out) {
+		fmt.Fprintln(out, "Changes not applied.")
+		return nil
+	}
+
+	backup, err := writeWithBackup(path, revised)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✅ Updated %s (backup in %s)\n", path, backup)
+	return nil
+}
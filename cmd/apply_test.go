@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+)
+
+// TestReviseFile checks the revised file is taken from the code block
+func TestReviseFile(t *testing.T) {
+	server := newFakeModel(t, func(string) string {
+		return "Here you go:\n\n```yaml\nimage: nginx:1.27\n```\n"
+	})
+
+	revised, err := reviseFile(server.URL, "key", "model", "image: nginx\n", filetype.Kubernetes, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revised != "image: nginx:1.27\n" {
+		t.Errorf("unexpected revised file %q", revised)
+	}
+}
+
+// TestReviseFileNoCodeBlock checks a reply without a code block is never
+// taken as the revised file
+func TestReviseFileNoCodeBlock(t *testing.T) {
+	requests := 0
+	server := newFakeModel(t, func(string) string {
+		requests++
+		return "Pin the image to a version."
+	})
+
+	if _, err := reviseFile(server.URL, "key", "model", "image: nginx\n", filetype.Kubernetes, ""); err == nil {
+		t.Fatalf("expected an error for a reply without a code block")
+	}
+	if requests != validationRetries()+1 {
+		t.Errorf("expected the model to be asked again, got %d requests", requests)
+	}
+}
+
+// TestReviewChanges checks the patch, confirmation and backup
+func TestReviewChanges(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deployment.yaml")
+	patch := filepath.Join(dir, "changes.patch")
+	original := "kind: Deployment\nimage: nginx\n"
+	revised := "kind: Deployment\nimage: nginx:1.27\n"
+	os.WriteFile(path, []byte(original), 0644)
+
+	var out bytes.Buffer
+	if err := reviewChanges(path, original, revised, patch, true, false, strings.NewReader("n\n"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != original {
+		t.Errorf("expected the file to be unchanged without confirmation")
+	}
+	diff, err := os.ReadFile(patch)
+	if err != nil || !strings.Contains(string(diff), "-image: nginx\n+image: nginx:1.27\n") {
+		t.Errorf("unexpected patch %q (%v)", diff, err)
+	}
+
+	if err := reviewChanges(path, original, revised, "", true, false, strings.NewReader("y\n"), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != revised {
+		t.Errorf("expected the revised file to be written, got %q", data)
+	}
+	if data, _ := os.ReadFile(path + ".bak"); string(data) != original {
+		t.Errorf("expected the original in the backup, got %q", data)
+	}
+}
+
+// TestDiffPath checks diff headers use paths relative to the working
+// directory and never a double slash
+func TestDiffPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := diffPath(filepath.Join(wd, "k8s", "app.yaml")); got != "k8s/app.yaml" {
+		t.Errorf("expected a path relative to the working directory, got %q", got)
+	}
+	if got := diffPath("k8s/app.yaml"); got != "k8s/app.yaml" {
+		t.Errorf("expected the relative path unchanged, got %q", got)
+	}
+
+	outside := filepath.Join(t.TempDir(), "app.yaml")
+	diff := unifiedDiff(outside, "a: 1\n", "a: 2\n")
+	if strings.Contains(diff, "a//") || !strings.Contains(diff, "--- a/"+strings.TrimPrefix(filepath.ToSlash(outside), "/")) {
+		t.Errorf("unexpected diff header for a path outside the working directory:\n%s", diff)
+	}
+}
+
+// TestExtractCodeBlock checks responses with and without a code block
+func TestExtractCodeBlock(t *testing.T) {
+	if got := extractCodeBlock("```\na: 1\n```"); got != "a: 1" {
+		t.Errorf("unexpected code block %q", got)
+	}
+	if got := extractCodeBlock("  a: 1\n"); got != "a: 1" {
+		t.Errorf("expected the whole response without a code block, got %q", got)
+	}
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// attachment is a file embedded into a message
+type attachment struct {
+	Path    string
+	Content string
+}
+
+// Fenced code block languages by file extension
+var fenceLanguages = map[string]string{
+	".yaml": "yaml",
+	".yml":  "yaml",
+	".json": "json",
+	".tf":   "hcl",
+	".hcl":  "hcl",
+	".sh":   "bash",
+	".bash": "bash",
+	".py":   "python",
+	".go":   "go",
+	".js":   "javascript",
+	".ts":   "typescript",
+	".toml": "toml",
+	".ini":  "ini",
+	".xml":  "xml",
+	".sql":  "sql",
+	".md":   "markdown",
+	".conf": "nginx",
+	".env":  "dotenv",
+	".log":  "text",
+}
+
+// fenceLanguage returns the language hint for a fenced code block
+func fenceLanguage(path string) string {
+	base := strings.ToLower(filepath.Base(path))
+	switch {
+	case base == "dockerfile" || strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile"):
+		return "dockerfile"
+	case base == "makefile":
+		return "makefile"
+	case base == "jenkinsfile":
+		return "groovy"
+	}
+	return fenceLanguages[strings.ToLower(filepath.Ext(path))]
+}
+
+// expandAttachments expands paths and glob patterns into a list of files
+func expandAttachments(patterns []string) ([]string, error) {
+	paths := []string{}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %q", pattern)
+		}
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && info.IsDir() {
+				continue
+			}
+			paths = append(paths, match)
+		}
+	}
+	return uniqueStrings(paths), nil
+}
+
+// loadAttachments reads the files matching the patterns. The combined size
+// is limited by input.max_size and binary files are rejected.
+func loadAttachments(patterns []string) ([]attachment, error) {
+	paths, err := expandAttachments(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := maxInputSize()
+	total := int64(0)
+	attachments := []attachment{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+			return nil, fmt.Errorf("%s looks like a binary file", path)
+		}
+
+		total += int64(len(data))
+		if total > limit {
+			return nil, fmt.Errorf("attachments are larger than the %d byte limit, raise input.max_size in config.yaml", limit)
+		}
+		attachments = append(attachments, attachment{Path: path, Content: string(data)})
+	}
+	return attachments, nil
+}
+
+// formatAttachment formats a file as a fenced block with its name
+func formatAttachment(a attachment) string {
+	// Use a longer fence when the content contains one itself
+	fence := "```"
+	for strings.Contains(a.Content, fence) {
+		fence += "`"
+	}
+	return fmt.Sprintf("File: %s\n%s%s\n%s\n%s", a.Path, fence, fenceLanguage(a.Path), strings.TrimRight(a.Content, "\n"), fence)
+}
+
+// withAttachments appends formatted attachments to a message
+func withAttachments(message string, attachments []attachment) string {
+	if len(attachments) == 0 {
+		return message
+	}
+	parts := []string{message}
+	for _, a := range attachments {
+		parts = append(parts, formatAttachment(a))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// attachmentNames returns the paths of the attachments
+func attachmentNames(attachments []attachment) []string {
+	names := []string{}
+	for _, a := range attachments {
+		names = append(names, a.Path)
+	}
+	return names
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestLoadAttachments checks glob expansion, formatting and limits
+func TestLoadAttachments(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("kind: Deployment\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "service.yaml"), []byte("kind: Service\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0644)
+
+	attachments, err := loadAttachments([]string{filepath.Join(dir, "*.yaml"), filepath.Join(dir, "Dockerfile")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(attachments) != 3 {
+		t.Fatalf("expected 3 attachments, got %d", len(attachments))
+	}
+
+	message := withAttachments("why does this Deployment not match this Service?", attachments)
+	for _, expected := range []string{"File: " + filepath.Join(dir, "deployment.yaml"), "```yaml\nkind: Deployment\n```", "```dockerfile\nFROM alpine\n```"} {
+		if !strings.Contains(message, expected) {
+			t.Errorf("expected message to contain %q", expected)
+		}
+	}
+
+	if _, err := loadAttachments([]string{filepath.Join(dir, "*.json")}); err == nil {
+		t.Errorf("expected error when nothing matches")
+	}
+
+	viper.Set("input.max_size", "20B")
+	t.Cleanup(func() { viper.Set("input.max_size", "") })
+	if _, err := loadAttachments([]string{filepath.Join(dir, "*.yaml")}); err == nil {
+		t.Errorf("expected error when attachments exceed the size limit")
+	}
+}
+
+// TestFormatAttachmentNestedFence checks content containing a fence
+func TestFormatAttachmentNestedFence(t *testing.T) {
+	formatted := formatAttachment(attachment{Path: "README.md", Content: "```sh\nls\n```"})
+	if !strings.HasPrefix(formatted, "File: README.md\n````markdown") || !strings.HasSuffix(formatted, "\n````") {
+		t.Errorf("expected a longer fence, got %q", formatted)
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/spf13/viper"
+)
+
+// defaultConcurrency is used when optimize.concurrency is not configured
+const defaultConcurrency = 4
+
+// batchResult is the outcome of processing one file or chunk
+type batchResult struct {
+	Name   string
+	Output string
+	Err    error
+}
+
+// isBatchTarget reports whether path names a directory or a glob instead of
+// a single file
+func isBatchTarget(path string) bool {
+	if strings.ContainsAny(path, "*?[") {
+		return true
+	}
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// supportedFile reports whether a file type can be optimized, looking at
+// the start of the file when the name is not enough
+func supportedFile(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(file, head)
+	return filetype.Detect(path, string(head[:n])) != filetype.Unknown
+}
+
+// discoverFiles returns the supported files in a directory or matching a
+// glob. Sub directories are only searched when recursive is set.
+func discoverFiles(target string, recursive bool) ([]string, error) {
+	roots := []string{target}
+	if strings.ContainsAny(target, "*?[") {
+		matches, err := filepath.Glob(target)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", target, err)
+		}
+		roots = matches
+	}
+
+	files := []string{}
+	for _, root := range roots {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if supportedFile(root) {
+				files = append(files, root)
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && (!recursive || strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if supportedFile(path) {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	files = uniqueStrings(files)
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no supported files found in %s", target)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// batchConcurrency returns the number of workers to use, falling back to
+// optimize.concurrency in the config
+func batchConcurrency(flagValue int) int {
+	if flagValue > 0 {
+		return flagValue
+	}
+	if n := viper.GetInt("optimize.concurrency"); n > 0 {
+		return n
+	}
+	return defaultConcurrency
+}
+
+// processFiles runs process for every file using a bounded pool of workers.
+// Results are returned in the order of files and progress is written to
+// progress as each file completes.
+func processFiles(files []string, workers int, progress io.Writer, process func(path string) (string, error)) []batchResult {
+	return runPool(files, workers, progress, func(i int) (string, error) {
+		return process(files[i])
+	})
+}
+
+// runPool runs process for every label using a bounded pool of workers and
+// writes a progress line as each one completes
+func runPool(labels []string, workers int, progress io.Writer, process func(i int) (string, error)) []batchResult {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(labels) {
+		workers = len(labels)
+	}
+
+	results := make([]batchResult, len(labels))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	done := 0
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				output, err := process(i)
+				results[i] = batchResult{Name: labels[i], Output: output, Err: err}
+
+				mu.Lock()
+				done++
+				status := "✅"
+				if err != nil {
+					status = "❌"
+				}
+				fmt.Fprintf(progress, "%s [%d/%d] %s\n", status, done, len(labels), labels[i])
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range labels {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+// batchReport combines the successful results into one Markdown report
+// grouped by file and returns the failed results
+func batchReport(results []batchResult) (string, []batchResult) {
+	var b strings.Builder
+	failed := []batchResult{}
+
+	b.WriteString("# Optimization Report\n\n")
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", result.Name, strings.TrimSpace(result.Output))
+	}
+
+	return b.String(), failed
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestDiscoverFiles checks directory, recursive and glob discovery
+func TestDiscoverFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "base"), 0755)
+	os.WriteFile(filepath.Join(dir, "deployment.yaml"), []byte("kind: Deployment\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("notes\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Dockerfile"), []byte("FROM alpine\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "base", "service.yml"), []byte("kind: Service\n"), 0644)
+
+	files, err := discoverFiles(dir, false)
+	if err != nil || len(files) != 2 {
+		t.Errorf("expected 2 files without --recursive, got %v (%v)", files, err)
+	}
+
+	files, err = discoverFiles(dir, true)
+	if err != nil || len(files) != 3 {
+		t.Errorf("expected 3 files with --recursive, got %v (%v)", files, err)
+	}
+
+	files, err = discoverFiles(filepath.Join(dir, "*", "*.yml"), false)
+	if err != nil || len(files) != 1 || !strings.HasSuffix(files[0], "service.yml") {
+		t.Errorf("expected service.yml for glob, got %v (%v)", files, err)
+	}
+
+	if _, err := discoverFiles(filepath.Join(dir, "*.txt"), false); err == nil {
+		t.Errorf("expected error when no supported files match")
+	}
+}
+
+// TestProcessFiles checks results keep their order and errors do not stop
+// the remaining files
+func TestProcessFiles(t *testing.T) {
+	files := []string{"a.yaml", "b.yaml", "c.yaml", "d.yaml"}
+	var running, maxRunning int32
+	var progress bytes.Buffer
+
+	results := processFiles(files, 2, &progress, func(path string) (string, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			current := atomic.LoadInt32(&maxRunning)
+			if n <= current || atomic.CompareAndSwapInt32(&maxRunning, current, n) {
+				break
+			}
+		}
+		if path == "b.yaml" {
+			return "", fmt.Errorf("timeout")
+		}
+		return "suggestions for " + path, nil
+	})
+
+	if maxRunning > 2 {
+		t.Errorf("expected at most 2 workers, got %d", maxRunning)
+	}
+	for i, result := range results {
+		if result.Name != files[i] {
+			t.Errorf("expected result %d for %s, got %s", i, files[i], result.Name)
+		}
+	}
+	if strings.Count(progress.String(), "\n") != len(files) {
+		t.Errorf("expected a progress line per file, got %q", progress.String())
+	}
+
+	report, failed := batchReport(results)
+	if len(failed) != 1 || failed[0].Name != "b.yaml" {
+		t.Errorf("expected b.yaml to fail, got %v", failed)
+	}
+	if !strings.Contains(report, "## d.yaml\n\nsuggestions for d.yaml") || strings.Contains(report, "## b.yaml") {
+		t.Errorf("unexpected report: %s", report)
+	}
+}
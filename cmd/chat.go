@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// Flags
+var chatConversationID int
+var chatModel string
+
+const chatHelp = `Commands:
+  /model [name]     Show or switch the model
+  /attach <file>    Attach a file (or glob) to the next message
+  /save [tags...]   Save the conversation, optionally adding tags
+  /fork [turn]      Fork the conversation (at a turn) and continue in the fork
+  /clear            Start a new conversation
+  /help             Show this help
+  /exit             Leave the chat
+
+End a line with \ to continue on the next line, or wrap multi-line input in """.`
+
+// chatSession holds the state of an interactive chat
+type chatSession struct {
+	apiHost     string
+	apiKey      string
+	model       string
+	cid         int
+	title       string
+	history     []map[string]string
+	attachments []attachment
+	tags        []string
+	out         io.Writer
+}
+
+// lineReader reads a line of input using a prompt
+type lineReader interface {
+	ReadLine(prompt string) (string, error)
+}
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Start an interactive chat with OpenWebUI",
+	Long: `Start an interactive chat session. Replies are streamed as they arrive and
+every turn is saved to the same conversation store used by the query command.
+Use --cid to continue a stored conversation. Type /help for the slash commands.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		runChat(chatConversationID, chatModel)
+	},
+}
+
+func init() {
+	chatCmd.Flags().IntVarP(&chatConversationID, "cid", "c", 0, "Continue a stored conversation ID")
+	chatCmd.Flags().StringVarP(&chatModel, "model", "m", "", "Model to use instead of openwebui.model")
+	rootCmd.AddCommand(chatCmd)
+}
+
+// runChat starts an interactive chat, continuing the conversation cid when
+// it is set and using model instead of openwebui.model when it is set
+func runChat(cid int, model string) {
+	apiHost, apiKey, aiModel, err := openWebUISettings()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if model != "" {
+		aiModel = model
+	}
+
+	session := &chatSession{apiHost: apiHost, apiKey: apiKey, model: aiModel, out: os.Stdout}
+	if cid > 0 {
+		if err := session.load(cid); err != nil {
+			fmt.Printf("Error loading conversation ID %d: %v\n", cid, err)
+			os.Exit(1)
+		}
+		fmt.Printf("💬 Continuing conversation ID %d (%d turn(s))\n", session.cid, countTurns(session.history))
+	}
+
+	fmt.Printf("💬 Chatting with %s. Type /help for commands, /exit to leave.\n\n", session.model)
+	session.run(newLineReader())
+}
+
+// run reads input until /exit or end of input
+func (s *chatSession) run(reader lineReader) {
+	for {
+		input, err := readChatInput(reader)
+		if err != nil {
+			fmt.Fprintln(s.out, "\n👋 Bye!")
+			return
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+
+		if strings.HasPrefix(input, "/") {
+			if !s.handleCommand(input) {
+				fmt.Fprintln(s.out, "👋 Bye!")
+				return
+			}
+			continue
+		}
+
+		s.send(input)
+	}
+}
+
+// load continues a stored conversation
+func (s *chatSession) load(id int) error {
+	conversations := loadAllConversations()
+	for _, conv := range conversations.List {
+		if conv.ID == id {
+			s.cid = conv.ID
+			s.title = conv.Query
+			s.history = conv.History
+			return nil
+		}
+	}
+	return fmt.Errorf("conversation not found")
+}
+
+// send sends a message with any pending attachments and streams the reply
+func (s *chatSession) send(message string) {
+	content := withAttachments(message, s.attachments)
+
+	s.history = append(s.history, map[string]string{"role": "user", "content": content})
+	logger.Log(fmt.Sprintf("chat: using %s model, conversation ID: %d", s.model, s.cid))
+
+	fmt.Fprintln(s.out)
+	response, err := streamQueryToOpenWebUI(s.apiHost, s.apiKey, s.model, s.history, func(chunk string) {
+		fmt.Fprint(s.out, chunk)
+	})
+	fmt.Fprint(s.out, "\n\n")
+	if err != nil {
+		// Drop the unanswered message so it can be retried
+		s.history = s.history[:len(s.history)-1]
+		fmt.Fprintf(s.out, "Error from OpenWebUI: %v\n\n", err)
+		return
+	}
+
+	s.history = append(s.history, map[string]string{"role": "assistant", "content": response})
+	if s.title == "" {
+		s.title = message
+	}
+	s.cid = saveConversation(s.history, s.cid, s.title, s.model, s.tags, attachmentNames(s.attachments))
+	s.attachments = nil
+}
+
+// handleCommand runs a slash command and returns false when the chat
+// should end
+func (s *chatSession) handleCommand(input string) bool {
+	fields := strings.Fields(input)
+	command, args := fields[0], fields[1:]
+
+	switch command {
+	case "/exit", "/quit":
+		return false
+
+	case "/help":
+		fmt.Fprintln(s.out, chatHelp)
+
+	case "/model":
+		if len(args) == 0 {
+			fmt.Fprintf(s.out, "Current model: %s\n", s.model)
+		} else {
+			s.model = args[0]
+			fmt.Fprintf(s.out, "✅ Switched to %s\n", s.model)
+		}
+
+	case "/attach":
+		if len(args) == 0 {
+			fmt.Fprintln(s.out, "Usage: /attach <file>")
+			break
+		}
+		path := strings.Join(args, " ")
+		attachments, err := loadAttachments([]string{path})
+		if err != nil {
+			fmt.Fprintf(s.out, "Error attaching %s: %v\n", path, err)
+			break
+		}
+		s.attachments = append(s.attachments, attachments...)
+		for _, a := range attachments {
+			fmt.Fprintf(s.out, "📎 %s will be sent with your next message\n", a.Path)
+		}
+
+	case "/save":
+		if len(s.history) == 0 {
+			fmt.Fprintln(s.out, "Nothing to save yet.")
+			break
+		}
+		s.tags = mergeTags(s.tags, args)
+		s.cid = saveConversation(s.history, s.cid, s.title, s.model, s.tags, nil)
+		fmt.Fprintf(s.out, "💾 Saved as conversation ID %d\n", s.cid)
+
+	case "/fork":
+		if s.cid == 0 {
+			fmt.Fprintln(s.out, "Nothing to fork yet.")
+			break
+		}
+		turn := 0
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				fmt.Fprintln(s.out, "Usage: /fork [turn]")
+				break
+			}
+			turn = n
+		}
+		fork, err := forkConversation(s.cid, turn)
+		if err != nil {
+			fmt.Fprintf(s.out, "Error forking conversation: %v\n", err)
+			break
+		}
+		fmt.Fprintf(s.out, "🍴 Forked conversation ID %d at turn %d, now in conversation ID %d\n", s.cid, fork.ForkedAt, fork.ID)
+		s.cid = fork.ID
+		s.history = fork.History
+
+	case "/clear":
+		s.cid = 0
+		s.title = ""
+		s.history = nil
+		s.attachments = nil
+		s.tags = nil
+		fmt.Fprintln(s.out, "🧹 Started a new conversation")
+
+	default:
+		fmt.Fprintf(s.out, "Unknown command %s, type /help for commands\n", command)
+	}
+	return true
+}
+
+// readChatInput reads one message. Lines ending in \ continue on the next
+// line and input between """ markers is read as a single message.
+func readChatInput(reader lineReader) (string, error) {
+	line, err := reader.ReadLine("you> ")
+	if err != nil {
+		return "", err
+	}
+
+	if strings.TrimSpace(line) == `"""` {
+		lines := []string{}
+		for {
+			next, err := reader.ReadLine("... ")
+			if err != nil {
+				return "", err
+			}
+			if strings.TrimSpace(next) == `"""` {
+				return strings.Join(lines, "\n"), nil
+			}
+			lines = append(lines, next)
+		}
+	}
+
+	lines := []string{}
+	for strings.HasSuffix(line, `\`) {
+		lines = append(lines, strings.TrimSuffix(line, `\`))
+		line, err = reader.ReadLine("... ")
+		if err != nil {
+			return "", err
+		}
+	}
+	lines = append(lines, line)
+	return strings.Join(lines, "\n"), nil
+}
+
+// newLineReader returns a line editing reader for terminals and a plain
+// reader when input is piped
+func newLineReader() lineReader {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		return &terminalReader{
+			fd: fd,
+			terminal: term.NewTerminal(struct {
+				io.Reader
+				io.Writer
+			}{os.Stdin, os.Stdout}, ""),
+		}
+	}
+	return &scannerReader{scanner: bufio.NewScanner(os.Stdin)}
+}
+
+// terminalReader provides line editing and input history on a terminal
+type terminalReader struct {
+	fd       int
+	terminal *term.Terminal
+}
+
+func (r *terminalReader) ReadLine(prompt string) (string, error) {
+	// Raw mode is only enabled while reading so streamed replies print normally
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(r.fd, state)
+
+	if width, height, err := term.GetSize(r.fd); err == nil {
+		r.terminal.SetSize(width, height)
+	}
+	r.terminal.SetPrompt(prompt)
+	return r.terminal.ReadLine()
+}
+
+// scannerReader reads lines from piped input
+type scannerReader struct {
+	scanner *bufio.Scanner
+}
+
+func (r *scannerReader) ReadLine(prompt string) (string, error) {
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeLineReader returns prepared lines and then io.EOF
+type fakeLineReader struct {
+	lines []string
+}
+
+func (r *fakeLineReader) ReadLine(prompt string) (string, error) {
+	if len(r.lines) == 0 {
+		return "", io.EOF
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	return line, nil
+}
+
+// newStreamingServer replies to every request with a streamed answer
+func newStreamingServer(t *testing.T, reply ...string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range reply {
+			fmt.Fprintf(w, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestReadChatInputMultiLine checks both multi-line input styles
+func TestReadChatInputMultiLine(t *testing.T) {
+	input, err := readChatInput(&fakeLineReader{lines: []string{`first \`, "second"}})
+	if err != nil || input != "first \nsecond" {
+		t.Errorf("unexpected continuation input %q (%v)", input, err)
+	}
+
+	input, err = readChatInput(&fakeLineReader{lines: []string{`"""`, "a", "b", `"""`}})
+	if err != nil || input != "a\nb" {
+		t.Errorf("unexpected block input %q (%v)", input, err)
+	}
+}
+
+// TestStreamQueryToOpenWebUI checks that streamed chunks are combined
+func TestStreamQueryToOpenWebUI(t *testing.T) {
+	server := newStreamingServer(t, "Hello", ", world")
+
+	chunks := []string{}
+	response, err := streamQueryToOpenWebUI(server.URL, "key", "model", nil, func(chunk string) {
+		chunks = append(chunks, chunk)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response != "Hello, world" || len(chunks) != 2 {
+		t.Errorf("unexpected response %q from chunks %v", response, chunks)
+	}
+}
+
+// TestChatSessionRun checks that turns are saved and slash commands work
+func TestChatSessionRun(t *testing.T) {
+	useTempSessionFile(t)
+	server := newStreamingServer(t, "pong")
+
+	var out bytes.Buffer
+	session := &chatSession{apiHost: server.URL, apiKey: "key", model: "gemma:2b", out: &out}
+	session.run(&fakeLineReader{lines: []string{
+		"ping",
+		"/model llama3",
+		"ping again",
+		"/save incident",
+		"/fork 1",
+		"/exit",
+	}})
+
+	conversations := loadAllConversations()
+	if len(conversations.List) != 2 {
+		t.Fatalf("expected conversation and fork to be stored, got %d", len(conversations.List))
+	}
+
+	conv := conversations.List[0]
+	if len(conv.History) != 4 || conv.Model != "llama3" || !hasTag(conv.Tags, "incident") {
+		t.Errorf("unexpected stored conversation: %+v", conv)
+	}
+	if session.cid != conversations.List[1].ID || len(session.history) != 2 {
+		t.Errorf("expected session to continue in the fork")
+	}
+	if !strings.Contains(out.String(), "pong") {
+		t.Errorf("expected streamed reply in output, got %q", out.String())
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/chunk"
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/ruanbekker/devops-ai-cli/internal/redact"
+	"github.com/spf13/viper"
+)
+
+// defaultMaxTokens is used when chunking.max_tokens is not configured
+const defaultMaxTokens = 6000
+
+const reducePrompt = `The answers below were each given for one part of the same %s.
+Combine them into a single answer to the original request. Merge duplicate
+findings, keep the most specific advice and mention which part (lines,
+documents or time window) each finding applies to.
+
+Original request: %s
+
+%s`
+
+// maxChunkTokens returns the configured chunking.max_tokens
+func maxChunkTokens() int {
+	if n := viper.GetInt("chunking.max_tokens"); n > 0 {
+		return n
+	}
+	return defaultMaxTokens
+}
+
+// chunkKind picks how to split content of the given kind
+func chunkKind(kind filetype.Kind, content string) chunk.Kind {
+	switch {
+	case kind.IsYAML():
+		return chunk.YAML
+	case kind == filetype.Terraform || kind == filetype.HCL:
+		return chunk.Terraform
+	case chunk.LooksLikeLog(content):
+		return chunk.Log
+	}
+	return chunk.Text
+}
+
+// mapReduce sends every chunk with send, then asks the model to combine the
+// partial answers into one. name and subject describe the input in progress
+// output and prompts, and request is the original request. send should
+// redact with the same redactor, so placeholders match across the answers.
+func mapReduce(apiHost, apiKey, model string, redactor *redact.Redactor, chunks []chunk.Chunk, name, subject, request string, workers int, progress io.Writer, send func(c chunk.Chunk, part string) (string, error)) (string, error) {
+	labels := make([]string, len(chunks))
+	parts := make([]string, len(chunks))
+	for i, c := range chunks {
+		parts[i] = fmt.Sprintf("part %d of %d, %s", i+1, len(chunks), c.Label())
+		labels[i] = fmt.Sprintf("%s %s", name, parts[i])
+	}
+
+	fmt.Fprintf(progress, "🧩 Split %s into %d chunks of up to %d tokens\n", name, len(chunks), maxChunkTokens())
+	results := runPool(labels, workers, progress, func(i int) (string, error) {
+		return send(chunks[i], parts[i])
+	})
+
+	partials := []string{}
+	for i, result := range results {
+		if result.Err != nil {
+			return "", fmt.Errorf("%s: %w", parts[i], result.Err)
+		}
+		partials = append(partials, fmt.Sprintf("### Answer for %s\n\n%s", parts[i], strings.TrimSpace(result.Output)))
+	}
+
+	// Combine in rounds so each request stays within the token limit
+	for len(partials) > 1 {
+		groups := groupPartials(partials, maxChunkTokens())
+		fmt.Fprintf(progress, "🔗 Combining %d partial answers\n", len(partials))
+
+		next := []string{}
+		for _, group := range groups {
+			if len(group) == 1 {
+				next = append(next, group[0])
+				continue
+			}
+			combined, err := sendRedactedQuery(apiHost, apiKey, model, redactor, []map[string]string{
+				{"role": "user", "content": fmt.Sprintf(reducePrompt, subject, request, strings.Join(group, "\n\n"))},
+			})
+			if err != nil {
+				return "", fmt.Errorf("combining answers: %w", err)
+			}
+			next = append(next, strings.TrimSpace(combined))
+		}
+		partials = next
+	}
+	return partials[0], nil
+}
+
+// groupPartials groups answers so each group fits in maxTokens. Every group
+// holds at least two answers so each round of combining makes progress.
+func groupPartials(partials []string, maxTokens int) [][]string {
+	groups := [][]string{}
+	current := []string{}
+	size := 0
+	for _, partial := range partials {
+		tokens := chunk.EstimateTokens(partial)
+		if len(current) >= 2 && size+tokens > maxTokens {
+			groups = append(groups, current)
+			current, size = nil, 0
+		}
+		current = append(current, partial)
+		size += tokens
+	}
+	return append(groups, current)
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ruanbekker/devops-ai-cli/internal/encryption"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Key material read from the environment or key command, cached so the
+// key command only runs once per invocation
+var cachedHistoryKey string
+
+var historyEncryptCmd = &cobra.Command{
+	Use:   "encrypt",
+	Short: "Encrypt the stored conversations",
+	Long: `Encrypts the conversation store using the settings in history.encryption.
+Use mode "passphrase" with a passphrase, or mode "age" with an age recipient and identity.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if historyEncryptionMode() == "" {
+			fmt.Println("Error: set history.encryption.mode to \"passphrase\" or \"age\" in config.yaml first.")
+			os.Exit(1)
+		}
+
+		conversations := loadAllConversations()
+		if err := writeConversations(conversations); err != nil {
+			fmt.Println("Error encrypting conversations:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🔒 Encrypted %d conversation(s) in %s\n", len(conversations.List), sessionFile)
+	},
+}
+
+var historyDecryptCmd = &cobra.Command{
+	Use:   "decrypt",
+	Short: "Decrypt the stored conversations",
+	Long: `Decrypts the conversation store back to plaintext JSON.
+Remove history.encryption.mode from config.yaml afterwards, or it will be encrypted again on the next save.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		conversations := loadAllConversations()
+		jsonData, err := json.MarshalIndent(conversations, "", "  ")
+		if err != nil {
+			fmt.Println("Error encoding conversations:", err)
+			os.Exit(1)
+		}
+		if err := writeSessionFile(jsonData); err != nil {
+			fmt.Println("Error writing conversations:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("🔓 Decrypted %d conversation(s) in %s\n", len(conversations.List), sessionFile)
+		if historyEncryptionMode() != "" {
+			fmt.Println("⚠️  history.encryption.mode is still set, conversations will be encrypted again on the next save.")
+		}
+	},
+}
+
+var historyKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an age identity for encrypting conversations",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		secret, public, err := encryption.GenerateIdentity()
+		if err != nil {
+			fmt.Println("Error generating identity:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("# public key: %s\n%s\n", public, secret)
+	},
+}
+
+func init() {
+	historyCmd.AddCommand(historyEncryptCmd)
+	historyCmd.AddCommand(historyDecryptCmd)
+	historyCmd.AddCommand(historyKeygenCmd)
+}
+
+// historyEncryptionMode returns the configured encryption mode, or "" when
+// conversations are stored in plaintext
+func historyEncryptionMode() string {
+	return strings.ToLower(strings.TrimSpace(viper.GetString("history.encryption.mode")))
+}
+
+// sessionFileEncrypted reports whether the session file on disk is encrypted
+func sessionFileEncrypted() bool {
+	data, err := os.ReadFile(sessionFile)
+	return err == nil && encryption.IsEncrypted(data)
+}
+
+// historyKey returns the passphrase or age identity from the environment
+// variable named by history.encryption.key_env, or the output of
+// history.encryption.key_command
+func historyKey() (string, error) {
+	if cachedHistoryKey != "" {
+		return cachedHistoryKey, nil
+	}
+
+	envName := viper.GetString("history.encryption.key_env")
+	if value := os.Getenv(envName); value != "" {
+		cachedHistoryKey = value
+		return cachedHistoryKey, nil
+	}
+
+	if command := viper.GetString("history.encryption.key_command"); command != "" {
+		out, err := exec.Command("sh", "-c", command).Output()
+		if err != nil {
+			return "", fmt.Errorf("running key command: %w", err)
+		}
+		cachedHistoryKey = strings.TrimSpace(string(out))
+		if cachedHistoryKey == "" {
+			return "", fmt.Errorf("key command returned no output")
+		}
+		return cachedHistoryKey, nil
+	}
+
+	return "", fmt.Errorf("no key found, set %s or history.encryption.key_command", envName)
+}
+
+// historyIdentities returns the identities used to decrypt the store. Keys
+// that look like age secret keys are used as identities, anything else is
+// treated as a passphrase.
+func historyIdentities() ([]age.Identity, error) {
+	key, err := historyKey()
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.Contains(key, "AGE-SECRET-KEY-") {
+		return encryption.ParseIdentities(key)
+	}
+	_, identity, err := encryption.PassphraseKeys(key)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Identity{identity}, nil
+}
+
+// historyRecipients returns the recipients used to encrypt the store
+func historyRecipients() ([]age.Recipient, error) {
+	switch mode := historyEncryptionMode(); mode {
+	case "passphrase":
+		key, err := historyKey()
+		if err != nil {
+			return nil, err
+		}
+		recipient, _, err := encryption.PassphraseKeys(key)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{recipient}, nil
+	case "age":
+		// A configured recipient is used as is, otherwise it is derived from
+		// the identity. Saves still need the identity, as the store is read
+		// before it is written.
+		if public := viper.GetString("history.encryption.recipient"); public != "" {
+			recipient, err := encryption.ParseRecipient(public)
+			if err != nil {
+				return nil, fmt.Errorf("invalid history.encryption.recipient: %w", err)
+			}
+			return []age.Recipient{recipient}, nil
+		}
+		identities, err := historyIdentities()
+		if err != nil {
+			return nil, err
+		}
+		recipients := encryption.RecipientsFor(identities)
+		if len(recipients) == 0 {
+			return nil, fmt.Errorf("no age identity found to derive a recipient from")
+		}
+		return recipients, nil
+	default:
+		return nil, fmt.Errorf("unknown history.encryption.mode %q, expected \"passphrase\" or \"age\"", mode)
+	}
+}
+
+// encryptHistory encrypts the serialized conversation store
+func encryptHistory(data []byte) ([]byte, error) {
+	recipients, err := historyRecipients()
+	if err != nil {
+		return nil, err
+	}
+	return encryption.Encrypt(data, recipients...)
+}
+
+// decryptHistory decrypts the serialized conversation store
+func decryptHistory(data []byte) ([]byte, error) {
+	identities, err := historyIdentities()
+	if err != nil {
+		return nil, err
+	}
+	return encryption.Decrypt(data, identities...)
+}
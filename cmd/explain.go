@@ -1,57 +1,57 @@
 package cmd
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 
+	"github.com/ruanbekker/devops-ai-cli/internal/chunk"
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
 	"github.com/ruanbekker/devops-ai-cli/internal/logger"
 	"github.com/charmbracelet/glamour"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+var explainAttachments []string
+
 var explainCmd = &cobra.Command{
-	Use:   "explain <query>",
+	Use:   "explain [query]",
 	Short: "Ask OpenWebUI for an explanation",
-	Long:  `Send a query to OpenWebUI and display the response in Markdown.`,
-	Args:  cobra.ExactArgs(1), // Require exactly one argument
+	Long: `Send a query to OpenWebUI and display the response in Markdown.
+Input piped on stdin is sent along with the query, e.g.
+  kubectl describe pod x | devopscli explain "why is this pending?"`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		query := args[0]
-
-		// Read API settings from config.yaml
-		apiHost := viper.GetString("openwebui.host")
-		apiKey := viper.GetString("openwebui.api_key")
-		aiModel := viper.GetString("openwebui.model")
+		// Read piped input, using the argument as the instruction
+		input, err := readStdin()
+		if err != nil {
+			fmt.Printf("Error reading stdin: %v\n", err)
+			os.Exit(1)
+		}
 
-		// If no API host in config, check environment variable
-		if apiHost == "" {
-			apiHost = os.Getenv("OPENWEB_API_HOST")
+		if len(args) == 0 && input == "" && len(explainAttachments) == 0 {
+			fmt.Println("Error: Please provide a query or pipe input on stdin.")
+			os.Exit(1)
 		}
 
-		// If no API key in config, check environment variable
-		if apiKey == "" {
-			apiKey = os.Getenv("OPENWEB_API_KEY")
+		question := "Explain the following input."
+		if len(args) > 0 {
+			question = args[0]
 		}
+		query := combineInput(question, input)
 
-		// Exit if no API key is found
-		if apiHost == "" || apiKey == "" {
-			fmt.Println("Error: OpenWebUI host and API key must be set in config.yaml or environment variable OPENWEB_API_KEY")
+		// Embed attached files into the query
+		attachments, err := loadAttachments(explainAttachments)
+		if err != nil {
+			fmt.Printf("Error reading attachments: %v\n", err)
 			os.Exit(1)
 		}
+		query = withAttachments(query, attachments)
 
-		// Construct API request payload
-		requestBody, err := json.Marshal(map[string]interface{}{
-			"model": aiModel,
-			"messages": []map[string]string{
-				{"role": "user", "content": query},
-			},
-		})
+		// Read API settings from config.yaml or environment variables
+		apiHost, apiKey, aiModel, err := openWebUISettings()
 		if err != nil {
-			fmt.Printf("Error creating JSON payload: %v\n", err)
+			fmt.Println("Error:", err)
 			os.Exit(1)
 		}
 
@@ -59,57 +59,28 @@ var explainCmd = &cobra.Command{
 		if viper.GetBool("debug") {
 			usageMessage := fmt.Sprintf("explain: using %s model.\n", aiModel)
 			logger.Log(usageMessage)
-                }
-
-		// Send API request
-		url := fmt.Sprintf("%s/api/chat/completions", apiHost)
-		req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
-		if err != nil {
-			fmt.Printf("Error creating request: %v\n", err)
-			os.Exit(1)
 		}
 
-		// Set headers
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Authorization", "Bearer "+apiKey)
-
-		client := &http.Client{}
-		resp, err := client.Do(req)
-		if err != nil {
-			fmt.Printf("Error sending request: %v\n", err)
-			os.Exit(1)
-		}
-		defer resp.Body.Close()
-
-		// Read response
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			fmt.Printf("Error reading response: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Parse JSON response
-		var jsonResponse struct {
-			Choices []struct {
-				Message struct {
-					Content string `json:"content"`
-				} `json:"message"`
-			} `json:"choices"`
+		var messageContent string
+		if chunks := chunk.Split(input, chunkKind(filetype.Detect("", input), input), maxChunkTokens()); len(chunks) > 1 {
+			// Explain large input in chunks and combine the answers
+			redactor := newRedactor()
+			messageContent, err = mapReduce(apiHost, apiKey, aiModel, redactor, chunks, "stdin", "input", question, batchConcurrency(0), os.Stderr, func(c chunk.Chunk, part string) (string, error) {
+				message := withAttachments(combineInput(fmt.Sprintf("%s\n\nThis is %s of the input.", question, part), c.Content), attachments)
+				return sendRedactedQuery(apiHost, apiKey, aiModel, redactor, []map[string]string{
+					{"role": "user", "content": message},
+				})
+			})
+		} else {
+			messageContent, err = sendQueryToOpenWebUI(apiHost, apiKey, aiModel, []map[string]string{
+				{"role": "user", "content": query},
+			})
 		}
-		err = json.Unmarshal(body, &jsonResponse)
 		if err != nil {
-			fmt.Printf("Error parsing JSON response: %v\n", err)
-			os.Exit(1)
-		}
-
-		if len(jsonResponse.Choices) == 0 {
-			fmt.Println("No response received from OpenWebUI.")
+			fmt.Printf("Error from OpenWebUI: %v\n", err)
 			os.Exit(1)
 		}
 
-		// Extract the message content
-		messageContent := jsonResponse.Choices[0].Message.Content
-
 		// Render response using Glamour
 		renderer, err := glamour.NewTermRenderer(
 			glamour.WithAutoStyle(),
@@ -131,6 +102,7 @@ var explainCmd = &cobra.Command{
 }
 
 func init() {
+	explainCmd.Flags().StringArrayVar(&explainAttachments, "attach", nil, "Attach a file or glob as context (repeatable)")
 	rootCmd.AddCommand(explainCmd)
 }
 
@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/chunk"
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/ruanbekker/devops-ai-cli/internal/report"
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+)
+
+const findingsPrompt = `Review this %s%s for problems.%s
+Reply with only a JSON array of findings, each an object with the fields
+"severity" (low, medium or high), "category" (security, reliability,
+performance, cost or readability), "line" (the line number shown before the
+code), "message" (the problem) and "suggestion" (how to fix it). Reply with []
+when there is nothing to improve.%s
+
+%s`
+
+// aiFinding is a finding as the model replies with it
+type aiFinding struct {
+	Severity   string      `json:"severity"`
+	Category   string      `json:"category"`
+	Line       lenientLine `json:"line"`
+	Message    string      `json:"message"`
+	Suggestion string      `json:"suggestion"`
+}
+
+// lenientLine accepts a line number written as a number or a string
+type lenientLine int
+
+func (l *lenientLine) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), `"`)
+	if n, err := strconv.Atoi(text); err == nil {
+		*l = lenientLine(n)
+	}
+	return nil
+}
+
+// parseAIFindings reads the JSON array of findings from a response
+func parseAIFindings(response string) ([]aiFinding, error) {
+	text := extractCodeBlock(response)
+	start, end := strings.Index(text, "["), strings.LastIndex(text, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("invalid JSON: no array of findings in the reply")
+	}
+
+	findings := []aiFinding{}
+	if err := json.Unmarshal([]byte(text[start:end+1]), &findings); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return findings, nil
+}
+
+// numberLines prefixes every line with its line number, starting at start
+func numberLines(content string, start int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = fmt.Sprintf("%4d | %s", start+i, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ruleFindings converts rule findings for a report
+func ruleFindings(name string, findings []rules.Finding) []report.Finding {
+	converted := []report.Finding{}
+	for _, f := range findings {
+		converted = append(converted, report.Finding{
+			Rule:       f.Rule,
+			Severity:   f.Severity,
+			Category:   f.Category,
+			File:       name,
+			Line:       f.Line,
+			Message:    f.Resource + ": " + f.Message,
+			Suggestion: f.Suggestion,
+			Source:     "rule",
+		})
+	}
+	return converted
+}
+
+// fileFindings returns the rule findings and the model's findings for a
+// file. Large files are reviewed in chunks, each with its own line range.
+// When the model fails the findings so far are returned with the error.
+func fileFindings(apiHost, apiKey, model, path string, r review) ([]report.Finding, error) {
+	content, err := readOptimizeInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading file: %w", err)
+	}
+
+	name := path
+	if path == "-" {
+		name = "stdin"
+	}
+	kind := filetype.Detect(path, content)
+	logger.Log(fmt.Sprintf("optimize: asking %s model for findings in %s (%s)", model, name, kind))
+
+	// The model is told about the rule findings so it does not repeat them
+	known := localFindings(kind, content, r.Rules)
+	findings := ruleFindings(name, known)
+	knownSection := ""
+	if len(known) > 0 {
+		knownSection = "\nThese problems are already known, do not repeat them:\n" + findingsList(known)
+	}
+	hint := ""
+	if h := optimizeHints[kind]; h != "" {
+		hint = " " + h
+	}
+
+	redactor := newRedactor()
+	chunks := chunk.Split(content, chunkKind(kind, content), maxChunkTokens())
+	for _, c := range chunks {
+		part := ""
+		if len(chunks) > 1 {
+			part = " (" + c.Label() + ")"
+		}
+		prompt := fmt.Sprintf(findingsPrompt, kind, part, hint, knownSection, numberLines(c.Content, c.StartLine))
+		if instruction := r.focusPrompt(false); instruction != "" {
+			prompt = instruction + "\n\n" + prompt
+		}
+
+		response, err := sendRedactedQuery(apiHost, apiKey, model, redactor, []map[string]string{
+			{"role": "user", "content": prompt},
+		})
+		if err != nil {
+			return findings, fmt.Errorf("from AI: %w", err)
+		}
+		// Ask again when the reply is not the JSON we asked for
+		response, err = revalidate(apiHost, apiKey, model, redactor, prompt, response, func(response string) error {
+			_, err := parseAIFindings(response)
+			return err
+		})
+		if err != nil {
+			return findings, fmt.Errorf("from AI: %w", err)
+		}
+
+		replied, _ := parseAIFindings(response)
+		for _, f := range replied {
+			severity, err := rules.ParseSeverity(f.Severity)
+			if err != nil {
+				severity = rules.Medium
+			}
+			category := strings.ToLower(strings.TrimSpace(f.Category))
+			if category == "" {
+				category = "general"
+			}
+			if !r.includes(category) {
+				continue
+			}
+			line := int(f.Line)
+			if line < c.StartLine || line > c.EndLine {
+				line = 0
+			}
+			findings = append(findings, report.Finding{
+				Rule:       "ai-" + category,
+				Severity:   severity,
+				Category:   category,
+				File:       name,
+				Line:       line,
+				Message:    f.Message,
+				Suggestion: f.Suggestion,
+				Source:     "ai",
+			})
+		}
+	}
+	return findings, nil
+}
+
+// runOptimizeFindings writes the findings for the files in optimizeFormat
+// and exits with status 1 when a file failed or a finding is at or above
+// failOn
+func runOptimizeFindings(apiHost, apiKey, model string, r review, failOn rules.Severity) {
+	files := []string{optimizeFilePath}
+	if optimizeFilePath != "-" && isBatchTarget(optimizeFilePath) {
+		found, err := discoverFiles(optimizeFilePath, optimizeRecursive)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error finding files: %v\n", err)
+			os.Exit(1)
+		}
+		files = found
+	}
+
+	names := make([]string, len(files))
+	for i, path := range files {
+		names[i] = path
+		if path == "-" {
+			names[i] = "stdin"
+		}
+	}
+
+	// Progress goes to stderr so stdout only holds the findings
+	var progress io.Writer = io.Discard
+	if len(files) > 1 {
+		progress = os.Stderr
+	}
+	perFile := make([][]report.Finding, len(files))
+	results := runPool(names, batchConcurrency(optimizeConcurrency), progress, func(i int) (string, error) {
+		findings, err := fileFindings(apiHost, apiKey, model, files[i], r)
+		perFile[i] = findings
+		return "", err
+	})
+
+	all := []report.Finding{}
+	checked := []string{}
+	failed := []batchResult{}
+	for i, result := range results {
+		// Rule findings are reported even when the model failed for a file
+		all = append(all, perFile[i]...)
+		if result.Err != nil {
+			failed = append(failed, result)
+			continue
+		}
+		checked = append(checked, result.Name)
+	}
+	report.Sort(all)
+
+	// The output file is closed before any exit, so a failed write is
+	// reported rather than lost
+	out := io.Writer(os.Stdout)
+	var file *os.File
+	if optimizeOutputPath != "" {
+		var err error
+		file, err = os.Create(optimizeOutputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating output file: %v\n", err)
+			os.Exit(1)
+		}
+		out = file
+	}
+	err := report.Write(out, optimizeFormat, all, checked, getVersion())
+	if file != nil {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing findings: %v\n", err)
+		os.Exit(1)
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(os.Stderr, "⚠️ %d file(s) failed:\n", len(failed))
+		for _, result := range failed {
+			fmt.Fprintf(os.Stderr, "  - %s: %v\n", result.Name, result.Err)
+		}
+		os.Exit(1)
+	}
+	if failOn != 0 {
+		if matched := report.AtLeast(all, failOn); len(matched) > 0 {
+			fmt.Fprintf(os.Stderr, "❌ %d finding(s) at or above %s severity\n", len(matched), failOn)
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+)
+
+// TestFileFindings checks rule findings and the model's findings are
+// combined and the known findings are passed to the model
+func TestFileFindings(t *testing.T) {
+	var prompt string
+	server := newFakeModel(t, func(p string) string {
+		prompt = p
+		return "```json\n[{\"severity\": \"HIGH\", \"category\": \"Security\", \"line\": \"4\", \"message\": \"name is too generic\", \"suggestion\": \"rename it\"}]\n```"
+	})
+
+	path := filepath.Join(t.TempDir(), "deployment.yaml")
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n      - name: web\n        image: nginx:latest\n"
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := fileFindings(server.URL, "key", "model", path, review{Rules: rules.Rules})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(prompt, "   4 |   name: web") || !strings.Contains(prompt, "do not repeat them") {
+		t.Errorf("expected numbered lines and the known findings in the prompt, got %q", prompt)
+	}
+
+	last := findings[len(findings)-1]
+	if last.Source != "ai" || last.Rule != "ai-security" || last.Severity != rules.High || last.Line != 4 || last.File != path {
+		t.Errorf("unexpected AI finding %+v", last)
+	}
+	if findings[0].Source != "rule" || findings[0].File != path {
+		t.Errorf("expected the rule findings first, got %+v", findings[0])
+	}
+}
+
+// TestParseAIFindings checks replies without a JSON array are rejected
+func TestParseAIFindings(t *testing.T) {
+	if findings, err := parseAIFindings("Nothing to improve: []"); err != nil || len(findings) != 0 {
+		t.Errorf("expected no findings, got %v, %v", findings, err)
+	}
+	if _, err := parseAIFindings("The file looks fine."); err == nil {
+		t.Errorf("expected an error for a reply without JSON")
+	}
+}
+
+// TestFileFindingsModelError checks the rule findings are kept when the
+// model cannot be reached
+func TestFileFindingsModelError(t *testing.T) {
+	server := newFakeModel(t, func(p string) string { return "" })
+	server.Close()
+
+	path := filepath.Join(t.TempDir(), "deployment.yaml")
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n      - name: web\n        image: nginx:latest\n"
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := fileFindings(server.URL, "key", "model", path, review{Rules: rules.Rules})
+	if err == nil {
+		t.Fatalf("expected an error when the model cannot be reached")
+	}
+	if len(findings) == 0 || findings[0].Source != "rule" {
+		t.Errorf("expected the rule findings with the error, got %+v", findings)
+	}
+}
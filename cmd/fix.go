@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+	"github.com/ruanbekker/devops-ai-cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var fixFilePath string
+var fixYes bool
+var fixDryRun bool
+var fixPatchPath string
+
+var fixCmd = &cobra.Command{
+	Use:   "fix -f <file>",
+	Short: "Apply rule-based fixes to a Kubernetes manifest without calling the model",
+	Long: `Fixes the lint findings that have a mechanical fix: resource requests and limits,
+imagePullPolicy for pinned images, a non-root security context, liveness and
+readiness probe templates and a PodDisruptionBudget for replicated workloads.
+Comments, ordering and formatting are kept. The changes are shown as a diff and
+written after you confirm (or straight away with --yes), keeping a .bak backup.
+Works without a reachable model.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if fixFilePath == "" || fixFilePath == "-" {
+			fmt.Println("Error: Please specify a file with -f")
+			os.Exit(1)
+		}
+
+		content, err := readOptimizeInput(fixFilePath)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+		if kind := filetype.Detect(fixFilePath, content); kind != filetype.Kubernetes {
+			fmt.Printf("Error: fix supports Kubernetes manifests, %s looks like a %s\n", fixFilePath, kind)
+			os.Exit(1)
+		}
+
+		fixed, changes, err := rules.Fix(content)
+		if err != nil {
+			fmt.Printf("Error parsing manifest: %v\n", err)
+			os.Exit(1)
+		}
+		// Never write a manifest the fixes broke
+		if err := validate.YAML(fixed); err != nil {
+			fmt.Printf("Error: the fixed manifest is not valid: %v\n", err)
+			os.Exit(1)
+		}
+
+		for _, change := range changes {
+			fmt.Printf("🔧 line %d %s %s: %s\n", change.Line, change.Rule, change.Resource, change.Description)
+		}
+		if len(changes) > 0 {
+			fmt.Println("")
+		}
+
+		if err := reviewChanges(fixFilePath, content, fixed, fixPatchPath, !fixDryRun, fixYes, os.Stdin, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Report what needs a decision, such as which image tag to use
+		if remaining, err := rules.Check(fixed, rules.Rules); err == nil && len(remaining) > 0 {
+			fmt.Println("\n⚠️ Still needs a manual fix:")
+			for _, f := range remaining {
+				fmt.Printf("   line %d [%s] %s %s: %s\n", f.Line, f.Severity, f.Rule, f.Resource, f.Message)
+			}
+		}
+	},
+}
+
+func init() {
+	fixCmd.Flags().StringVarP(&fixFilePath, "file", "f", "", "Kubernetes manifest to fix")
+	fixCmd.Flags().BoolVarP(&fixYes, "yes", "y", false, "Write the fixes without asking for confirmation")
+	fixCmd.Flags().BoolVar(&fixDryRun, "dry-run", false, "Only show the diff")
+	fixCmd.Flags().StringVar(&fixPatchPath, "output-patch", "", "Write the changes as a unified diff to this file")
+	rootCmd.AddCommand(fixCmd)
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+	"github.com/spf13/viper"
+)
+
+// focusAreas are the areas a review can focus on, in report order
+var focusAreas = []struct{ name, description string }{
+	{"security", "secrets, permissions, network exposure and running as root"},
+	{"reliability", "health checks, resource requests, replicas, disruption budgets and pinned versions"},
+	{"performance", "caching, resource limits, and build and startup time"},
+	{"cost", "over-provisioned resources, idle capacity and storage"},
+	{"readability", "structure, naming, comments and duplication"},
+}
+
+// reviewProfile is a named review setup from optimize.profiles in config
+type reviewProfile struct {
+	Focus       []string `mapstructure:"focus"`
+	Instruction string   `mapstructure:"instruction"`
+	Rules       []string `mapstructure:"rules"`
+}
+
+// review is what an optimize run asks for: the focus areas, the
+// instructions and the rules to run
+type review struct {
+	Focus       []string
+	Instruction string
+	Rules       []rules.Rule
+}
+
+// newReview combines the instruction argument, the --focus areas and a
+// review profile. Focus areas given as flags replace those of the profile.
+func newReview(instruction string, focus []string, profileName string) (review, error) {
+	r := review{Instruction: instruction, Rules: rules.All()}
+
+	var profile reviewProfile
+	if profileName != "" {
+		key := "optimize.profiles." + profileName
+		if !viper.IsSet(key) {
+			return r, fmt.Errorf("unknown review profile %q, define it under optimize.profiles in config.yaml", profileName)
+		}
+		if err := viper.UnmarshalKey(key, &profile); err != nil {
+			return r, fmt.Errorf("reading review profile %q: %w", profileName, err)
+		}
+		if profile.Instruction != "" {
+			r.Instruction = strings.TrimSpace(profile.Instruction + "\n\n" + instruction)
+		}
+	}
+
+	if len(focus) == 0 {
+		focus = profile.Focus
+	}
+	for _, area := range focus {
+		area = strings.ToLower(strings.TrimSpace(area))
+		if !isFocusArea(area) {
+			return r, fmt.Errorf("unknown focus area %q, use %s", area, strings.Join(focusAreaNames(), ", "))
+		}
+		r.Focus = append(r.Focus, area)
+	}
+
+	if len(profile.Rules) > 0 {
+		r.Rules = nil
+		for _, id := range profile.Rules {
+			rule, ok := rules.Find(id)
+			if !ok {
+				return r, fmt.Errorf("unknown rule %q in review profile %q", id, profileName)
+			}
+			r.Rules = append(r.Rules, rule)
+		}
+	} else if len(r.Focus) > 0 {
+		r.Rules = nil
+		for _, rule := range rules.All() {
+			if r.includes(rule.Category) {
+				r.Rules = append(r.Rules, rule)
+			}
+		}
+	}
+	return r, nil
+}
+
+// includes reports whether a category is part of the review, which is
+// every category when there is no focus
+func (r review) includes(category string) bool {
+	if len(r.Focus) == 0 {
+		return true
+	}
+	for _, area := range r.Focus {
+		if area == category {
+			return true
+		}
+	}
+	return false
+}
+
+// prompt returns the instruction for Markdown suggestions, grouped by
+// focus area when there is a focus
+func (r review) prompt() string {
+	return r.focusPrompt(true)
+}
+
+// focusPrompt returns the instruction with the focus areas, asking for a
+// heading per area when grouped is set
+func (r review) focusPrompt(grouped bool) string {
+	if len(r.Focus) == 0 {
+		return r.Instruction
+	}
+
+	var b strings.Builder
+	if r.Instruction != "" {
+		b.WriteString(r.Instruction + "\n\n")
+	}
+	b.WriteString("Only review these areas:\n")
+	headings := []string{}
+	for _, area := range focusAreas {
+		if r.includes(area.name) {
+			fmt.Fprintf(&b, "- %s: %s\n", area.name, area.description)
+			headings = append(headings, "## "+areaTitle(area.name))
+		}
+	}
+	if grouped {
+		fmt.Fprintf(&b, "Group your suggestions under a heading per area, in this order: %s.", strings.Join(headings, ", "))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// areaTitle returns the heading for a focus area
+func areaTitle(name string) string {
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// isFocusArea reports whether name is a known focus area
+func isFocusArea(name string) bool {
+	for _, area := range focusAreas {
+		if area.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// focusAreaNames returns the names of the focus areas
+func focusAreaNames() []string {
+	names := []string{}
+	for _, area := range focusAreas {
+		names = append(names, area.name)
+	}
+	return names
+}
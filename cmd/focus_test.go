@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestNewReviewFocus checks the focus areas select the rules and shape the
+// prompt
+func TestNewReviewFocus(t *testing.T) {
+	r, err := newReview("check the ingress", []string{"Security", "cost"}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, rule := range r.Rules {
+		if rule.Category != "security" && rule.Category != "cost" {
+			t.Errorf("unexpected rule %s in the rule set", rule.ID)
+		}
+	}
+	if len(r.Rules) == 0 {
+		t.Errorf("expected security and cost rules")
+	}
+
+	prompt := r.prompt()
+	if !strings.HasPrefix(prompt, "check the ingress") || !strings.Contains(prompt, "in this order: ## Security, ## Cost.") {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+	if strings.Contains(r.focusPrompt(false), "heading") {
+		t.Errorf("expected no headings when not grouped")
+	}
+
+	if _, err := newReview("", []string{"speed"}, ""); err == nil {
+		t.Errorf("expected an error for an unknown focus area")
+	}
+}
+
+// TestNewReviewProfile checks profiles from config and that --focus
+// replaces the focus of the profile
+func TestNewReviewProfile(t *testing.T) {
+	viper.Set("optimize.profiles.prod-hardening", map[string]interface{}{
+		"focus":       []string{"security", "reliability"},
+		"instruction": "This runs in production.",
+		"rules":       []string{"run-as-root", "host-path"},
+	})
+	t.Cleanup(func() { viper.Set("optimize.profiles.prod-hardening", nil) })
+
+	r, err := newReview("be brief", nil, "prod-hardening")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Focus) != 2 || len(r.Rules) != 2 || r.Rules[0].ID != "run-as-root" {
+		t.Errorf("unexpected review %+v", r)
+	}
+	if r.Instruction != "This runs in production.\n\nbe brief" {
+		t.Errorf("unexpected instruction %q", r.Instruction)
+	}
+
+	r, err = newReview("", []string{"cost"}, "prod-hardening")
+	if err != nil || len(r.Focus) != 1 || r.Focus[0] != "cost" {
+		t.Errorf("expected the focus flag to win, got %+v, %v", r, err)
+	}
+
+	if _, err := newReview("", nil, "missing"); err == nil {
+		t.Errorf("expected an error for an unknown profile")
+	}
+}
@@ -0,0 +1,398 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// Flags
+var searchSince string
+var searchUntil string
+var searchModel string
+var searchTag string
+var searchLimit int
+
+// Style used to highlight matched terms in snippets
+var highlightStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11"))
+
+// searchHit is a single conversation matching a search
+type searchHit struct {
+	Conversation Conversation
+	Score        float64
+	Snippets     []string
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Browse and manage stored conversations",
+	Long:  `Commands for working with the conversations stored by the query command.`,
+}
+
+var historySearchCmd = &cobra.Command{
+	Use:   "search <terms...>",
+	Short: "Search all stored conversations",
+	Long: `Search the user and assistant messages of every stored conversation.
+Results are ranked by relevance and show highlighted snippets of the matches.
+Continue a result with: devopscli query --cid <id> "<message>"`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filter, err := newSearchFilter(searchSince, searchUntil, searchModel, searchTag)
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		conversations := loadAllConversations()
+		hits := searchConversations(conversations.List, strings.Join(args, " "), filter)
+		if len(hits) == 0 {
+			fmt.Println("No matching conversations found.")
+			return
+		}
+
+		if searchLimit > 0 && len(hits) > searchLimit {
+			hits = hits[:searchLimit]
+		}
+
+		fmt.Printf("\n🔎 **%d matching conversation(s):**\n\n", len(hits))
+		for _, hit := range hits {
+			conv := hit.Conversation
+			fmt.Printf("🆔 %d: %s", conv.ID, conv.Query)
+			if !conv.UpdatedAt.IsZero() {
+				fmt.Printf("  (%s)", conv.UpdatedAt.Format("2006-01-02 15:04"))
+			}
+			fmt.Println()
+			for _, snippet := range hit.Snippets {
+				fmt.Printf("   %s\n", snippet)
+			}
+			fmt.Printf("   ↪ devopscli query --cid %d \"<message>\"\n\n", conv.ID)
+		}
+	},
+}
+
+func init() {
+	historySearchCmd.Flags().StringVar(&searchSince, "since", "", "Only conversations updated since a date (YYYY-MM-DD) or duration (e.g. 7d, 12h)")
+	historySearchCmd.Flags().StringVar(&searchUntil, "until", "", "Only conversations updated before a date (YYYY-MM-DD) or duration")
+	historySearchCmd.Flags().StringVarP(&searchModel, "model", "m", "", "Only conversations that used this model")
+	historySearchCmd.Flags().StringVarP(&searchTag, "tag", "t", "", "Only conversations with this tag")
+	historySearchCmd.Flags().IntVarP(&searchLimit, "limit", "n", 10, "Maximum number of results to show (0 for all)")
+	historyCmd.AddCommand(historySearchCmd)
+	rootCmd.AddCommand(historyCmd)
+}
+
+// searchFilter restricts which conversations are searched
+type searchFilter struct {
+	Since time.Time
+	Until time.Time
+	Model string
+	Tag   string
+}
+
+// newSearchFilter builds a searchFilter from command line values
+func newSearchFilter(since, until, model, tag string) (searchFilter, error) {
+	filter := searchFilter{Model: model, Tag: tag}
+
+	var err error
+	if since != "" {
+		if filter.Since, err = parseTimeBound(since, time.Now()); err != nil {
+			return filter, fmt.Errorf("invalid --since value %q: %w", since, err)
+		}
+	}
+	if until != "" {
+		if filter.Until, err = parseTimeBound(until, time.Now()); err != nil {
+			return filter, fmt.Errorf("invalid --until value %q: %w", until, err)
+		}
+	}
+	return filter, nil
+}
+
+// matches reports whether a conversation passes the filter
+func (f searchFilter) matches(conv Conversation) bool {
+	if !f.Since.IsZero() && conv.UpdatedAt.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !conv.UpdatedAt.Before(f.Until) {
+		return false
+	}
+	if f.Model != "" && !strings.EqualFold(conv.Model, f.Model) {
+		return false
+	}
+	if f.Tag != "" && !hasTag(conv.Tags, f.Tag) {
+		return false
+	}
+	return true
+}
+
+// hasTag checks if a tag is present, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeBound parses a date (YYYY-MM-DD), RFC3339 timestamp or a
+// duration relative to now such as 7d or 12h
+func parseTimeBound(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	d, err := parseAge(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now.Add(-d), nil
+}
+
+// parseAge parses a duration that may also be given in days, e.g. 30d
+func parseAge(value string) (time.Duration, error) {
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// tokenize splits text into lowercase words
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// searchConversations ranks conversations against the query terms.
+// Scoring is TF-IDF across conversations with a bonus for every distinct
+// term matched, for exact phrase matches and for matches in the title.
+func searchConversations(list []Conversation, query string, filter searchFilter) []searchHit {
+	terms := uniqueStrings(tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	phrase := strings.ToLower(strings.TrimSpace(query))
+
+	candidates := []Conversation{}
+	for _, conv := range list {
+		if filter.matches(conv) {
+			candidates = append(candidates, conv)
+		}
+	}
+
+	// Count term frequencies per conversation and document frequencies
+	termFreqs := make([]map[string]int, len(candidates))
+	docFreq := map[string]int{}
+	for i, conv := range candidates {
+		freqs := map[string]int{}
+		for _, msg := range conv.History {
+			for _, word := range tokenize(msg["content"]) {
+				freqs[word]++
+			}
+		}
+		termFreqs[i] = freqs
+		for _, term := range terms {
+			if freqs[term] > 0 {
+				docFreq[term]++
+			}
+		}
+	}
+
+	hits := []searchHit{}
+	for i, conv := range candidates {
+		score := 0.0
+		matched := 0
+		for _, term := range terms {
+			tf := termFreqs[i][term]
+			if tf == 0 {
+				continue
+			}
+			matched++
+			idf := math.Log(1 + float64(len(candidates))/float64(docFreq[term]))
+			score += (1 + math.Log(float64(tf))) * idf
+		}
+		if matched == 0 {
+			continue
+		}
+
+		score += float64(matched) * 10
+		if len(terms) > 1 && conversationContains(conv, phrase) {
+			score += 20
+		}
+		for _, word := range tokenize(conv.Query) {
+			if containsString(terms, word) {
+				score += 5
+			}
+		}
+
+		hits = append(hits, searchHit{
+			Conversation: conv,
+			Score:        score,
+			Snippets:     buildSnippets(conv, terms, 2),
+		})
+	}
+
+	sort.SliceStable(hits, func(a, b int) bool {
+		if hits[a].Score != hits[b].Score {
+			return hits[a].Score > hits[b].Score
+		}
+		return hits[a].Conversation.UpdatedAt.After(hits[b].Conversation.UpdatedAt)
+	})
+	return hits
+}
+
+// conversationContains checks if any message contains the lowercase phrase
+func conversationContains(conv Conversation, phrase string) bool {
+	for _, msg := range conv.History {
+		if strings.Contains(strings.ToLower(msg["content"]), phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSnippets returns up to max highlighted excerpts of matching messages
+func buildSnippets(conv Conversation, terms []string, max int) []string {
+	snippets := []string{}
+	for _, msg := range conv.History {
+		if len(snippets) >= max {
+			break
+		}
+		excerpt, ok := excerptAround(msg["content"], terms, 60)
+		if !ok {
+			continue
+		}
+		role := "you"
+		if msg["role"] == "assistant" {
+			role = "ai"
+		}
+		snippets = append(snippets, fmt.Sprintf("%s: %s", role, highlightTerms(excerpt, terms)))
+	}
+	return snippets
+}
+
+// excerptAround returns the text surrounding the first matched term
+func excerptAround(text string, terms []string, radius int) (string, bool) {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := []rune(strings.ToLower(string(runes)))
+
+	first := -1
+	for _, term := range terms {
+		if idx := indexRunes(lower, []rune(term)); idx >= 0 && (first < 0 || idx < first) {
+			first = idx
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+
+	start := first - radius
+	if start < 0 {
+		start = 0
+	}
+	end := first + radius
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	excerpt := string(runes[start:end])
+	if start > 0 {
+		excerpt = "…" + excerpt
+	}
+	if end < len(runes) {
+		excerpt += "…"
+	}
+	return excerpt, true
+}
+
+// highlightTerms styles every case-insensitive occurrence of the terms
+func highlightTerms(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	marked := make([]bool, len(runes))
+
+	for _, term := range terms {
+		t := []rune(term)
+		for offset := 0; offset < len(lower); {
+			idx := indexRunes(lower[offset:], t)
+			if idx < 0 {
+				break
+			}
+			for j := offset + idx; j < offset+idx+len(t); j++ {
+				marked[j] = true
+			}
+			offset += idx + len(t)
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		j := i
+		for j < len(runes) && marked[j] == marked[i] {
+			j++
+		}
+		if marked[i] {
+			b.WriteString(highlightStyle.Render(string(runes[i:j])))
+		} else {
+			b.WriteString(string(runes[i:j]))
+		}
+		i = j
+	}
+	return b.String()
+}
+
+// indexRunes returns the index of sub in s, or -1
+func indexRunes(s, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
+
+// uniqueStrings removes duplicates while keeping order
+func uniqueStrings(values []string) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// containsString checks if a slice contains a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func testConversations() []Conversation {
+	now := time.Now()
+	return []Conversation{
+		{
+			ID:    1,
+			Query: "What is Kubernetes?",
+			Model: "gemma:2b",
+			History: []map[string]string{
+				{"role": "user", "content": "What is Kubernetes?"},
+				{"role": "assistant", "content": "Kubernetes is a container orchestrator."},
+			},
+			UpdatedAt: now.AddDate(0, 0, -30),
+		},
+		{
+			ID:    2,
+			Query: "ingress returns 502",
+			Model: "llama3",
+			Tags:  []string{"incident"},
+			History: []map[string]string{
+				{"role": "user", "content": "Our ingress returns 502 errors after the deploy"},
+				{"role": "assistant", "content": "A 502 from the ingress usually means the backend pods are not ready."},
+			},
+			UpdatedAt: now,
+		},
+	}
+}
+
+// TestSearchConversationsRanking ensures the best match is ranked first
+func TestSearchConversationsRanking(t *testing.T) {
+	hits := searchConversations(testConversations(), "ingress 502", searchFilter{})
+	if len(hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(hits))
+	}
+	if hits[0].Conversation.ID != 2 {
+		t.Errorf("expected conversation 2, got %d", hits[0].Conversation.ID)
+	}
+	if len(hits[0].Snippets) == 0 {
+		t.Errorf("expected snippets for the hit")
+	}
+}
+
+// TestSearchConversationsFilter ensures filters exclude conversations
+func TestSearchConversationsFilter(t *testing.T) {
+	list := testConversations()
+
+	hits := searchConversations(list, "kubernetes ingress", searchFilter{Model: "gemma:2b"})
+	if len(hits) != 1 || hits[0].Conversation.ID != 1 {
+		t.Errorf("expected only conversation 1 for model filter, got %v", hits)
+	}
+
+	hits = searchConversations(list, "kubernetes ingress", searchFilter{Tag: "INCIDENT"})
+	if len(hits) != 1 || hits[0].Conversation.ID != 2 {
+		t.Errorf("expected only conversation 2 for tag filter, got %v", hits)
+	}
+
+	hits = searchConversations(list, "kubernetes ingress", searchFilter{Since: time.Now().AddDate(0, 0, -7)})
+	if len(hits) != 1 || hits[0].Conversation.ID != 2 {
+		t.Errorf("expected only conversation 2 for since filter, got %v", hits)
+	}
+}
+
+// TestParseTimeBound checks dates and relative durations
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	got, err := parseTimeBound("7d", now)
+	if err != nil || !got.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("expected 7 days before now, got %v (%v)", got, err)
+	}
+
+	got, err = parseTimeBound("12h", now)
+	if err != nil || !got.Equal(now.Add(-12*time.Hour)) {
+		t.Errorf("expected 12 hours before now, got %v (%v)", got, err)
+	}
+
+	if _, err := parseTimeBound("2025-01-02", now); err != nil {
+		t.Errorf("expected date to parse, got %v", err)
+	}
+
+	if _, err := parseTimeBound("yesterday", now); err == nil {
+		t.Errorf("expected error for invalid value")
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// defaultMaxInputSize is used when input.max_size is not configured
+const defaultMaxInputSize = 1 << 20
+
+// stdinIsPiped reports whether stdin is a pipe or file instead of a terminal
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// maxInputSize returns the configured input.max_size in bytes
+func maxInputSize() int64 {
+	value := viper.GetString("input.max_size")
+	if value == "" {
+		return defaultMaxInputSize
+	}
+	size, err := parseByteSize(value)
+	if err != nil || size <= 0 {
+		return defaultMaxInputSize
+	}
+	return size
+}
+
+// readLimited reads all input from r, failing when it is larger than
+// input.max_size
+func readLimited(r io.Reader, source string) (string, error) {
+	limit := maxInputSize()
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > limit {
+		return "", fmt.Errorf("%s is larger than the %d byte limit, raise input.max_size in config.yaml", source, limit)
+	}
+	return string(data), nil
+}
+
+// readStdin reads piped input, returning "" when stdin is a terminal
+func readStdin() (string, error) {
+	if !stdinIsPiped() {
+		return "", nil
+	}
+	input, err := readLimited(os.Stdin, "stdin")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(input, "\n"), nil
+}
+
+// combineInput joins an instruction with piped input as one message
+func combineInput(instruction, input string) string {
+	if strings.TrimSpace(input) == "" {
+		return instruction
+	}
+	return fmt.Sprintf("%s\n\nInput:\n```\n%s\n```", instruction, input)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// TestReadLimited checks that input above input.max_size is rejected
+func TestReadLimited(t *testing.T) {
+	viper.Set("input.max_size", "10B")
+	t.Cleanup(func() { viper.Set("input.max_size", "") })
+
+	if input, err := readLimited(strings.NewReader("small"), "stdin"); err != nil || input != "small" {
+		t.Errorf("expected small input to be read, got %q (%v)", input, err)
+	}
+	if _, err := readLimited(strings.NewReader("this is too large"), "stdin"); err == nil {
+		t.Errorf("expected error for input above the limit")
+	}
+}
+
+// TestCombineInput checks how instructions and piped input are joined
+func TestCombineInput(t *testing.T) {
+	if got := combineInput("why is this pending?", ""); got != "why is this pending?" {
+		t.Errorf("expected instruction only, got %q", got)
+	}
+
+	got := combineInput("why is this pending?", "Status: Pending")
+	if !strings.HasPrefix(got, "why is this pending?") || !strings.Contains(got, "```\nStatus: Pending\n```") {
+		t.Errorf("unexpected combined input %q", got)
+	}
+}
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+var lintFilePath string
+var lintRecursive bool
+var lintExplain bool
+
+const explainFindingsPrompt = `A linter found these problems in this %s. For each finding explain
+briefly why it matters, then show the fixed file in a single code block.
+
+%s
+
+%s`
+
+var lintCmd = &cobra.Command{
+	Use:   "lint -f <file>",
+	Short: "Check Kubernetes manifests and Dockerfiles against best-practice rules",
+	Long: `Runs local best-practice rules on every document in Kubernetes manifests:
+resource requests and limits, latest image tags, liveness and readiness probes,
+containers running as root, hostPath volumes and replicated workloads without a
+PodDisruptionBudget. Dockerfiles are checked for unpinned base images, apt-get
+installs without cleanup, running as root, ADD used for local files, a missing
+HEALTHCHECK and copying the source before installing dependencies. Findings
+are reported with their line, without calling the model. Use --explain to
+have the model explain the findings and suggest fixes. Files that cannot be
+read or parsed are reported and the other files are still checked. Exits with
+status 1 when there are findings or a file failed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read from stdin with -f - or when input is piped without -f
+		if lintFilePath == "" && stdinIsPiped() {
+			lintFilePath = "-"
+		}
+		if lintFilePath == "" {
+			fmt.Println("Error: Please specify a file with -f")
+			os.Exit(1)
+		}
+
+		files := []string{lintFilePath}
+		if lintFilePath != "-" && isBatchTarget(lintFilePath) {
+			found, err := discoverFiles(lintFilePath, lintRecursive)
+			if err != nil {
+				fmt.Printf("Error finding files: %v\n", err)
+				os.Exit(1)
+			}
+			files = found
+		}
+
+		total, failed := lintFiles(files, os.Stdout)
+
+		if len(failed) > 0 {
+			fmt.Printf("\n⚠️ %d file(s) failed:\n", len(failed))
+			for _, result := range failed {
+				fmt.Printf("  - %s: %v\n", result.Name, result.Err)
+			}
+		}
+		if total > 0 || len(failed) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	lintCmd.Flags().StringVarP(&lintFilePath, "file", "f", "", "File, directory or glob to lint, or - for stdin")
+	lintCmd.Flags().BoolVarP(&lintRecursive, "recursive", "r", false, "Include sub directories when linting a directory")
+	lintCmd.Flags().BoolVar(&lintExplain, "explain", false, "Ask the model to explain the findings and suggest fixes")
+	rootCmd.AddCommand(lintCmd)
+}
+
+// lintFiles runs the rules on every file and writes the findings to out.
+// Files that cannot be read or parsed are returned as failed and the other
+// files are still checked.
+func lintFiles(files []string, out io.Writer) (int, []batchResult) {
+	total := 0
+	failed := []batchResult{}
+	for _, path := range files {
+		name, detectName := path, path
+		if path == "-" {
+			name, detectName = "stdin", ""
+		}
+
+		content, err := readOptimizeInput(path)
+		if err != nil {
+			fmt.Fprintf(out, "❌ %s: %v\n", name, err)
+			failed = append(failed, batchResult{Name: name, Err: err})
+			continue
+		}
+		kind := filetype.Detect(detectName, content)
+		if !hasRules(kind) {
+			fmt.Fprintf(out, "⏭️ %s: no rules for %s\n", name, kind)
+			continue
+		}
+
+		findings, err := checkRules(kind, content, rules.All())
+		if err != nil {
+			fmt.Fprintf(out, "❌ %s: %v\n", name, err)
+			failed = append(failed, batchResult{Name: name, Err: err})
+			continue
+		}
+		if len(findings) == 0 {
+			fmt.Fprintf(out, "✅ %s\n", name)
+			continue
+		}
+		total += len(findings)
+
+		fmt.Fprintf(out, "⚠️ %s: %d finding(s)\n", name, len(findings))
+		for _, f := range findings {
+			fmt.Fprintf(out, "   line %d [%s] %s %s: %s\n", f.Line, f.Severity, f.Rule, f.Resource, f.Message)
+		}
+
+		if lintExplain {
+			explainFindings(kind, content, findings)
+		}
+	}
+	return total, failed
+}
+
+// hasRules reports whether there are local rules for a kind of file
+func hasRules(kind filetype.Kind) bool {
+	return kind == filetype.Kubernetes || kind == filetype.Dockerfile
+}
+
+// checkRules runs the rules of a rule set that apply to a kind of file
+func checkRules(kind filetype.Kind, content string, ruleSet []rules.Rule) ([]rules.Finding, error) {
+	if kind == filetype.Dockerfile {
+		return rules.CheckDockerfile(content, ruleSet)
+	}
+	return rules.Check(content, ruleSet)
+}
+
+// localFindings runs a rule set on Kubernetes manifests and Dockerfiles.
+// Other kinds and files that do not parse have no findings.
+func localFindings(kind filetype.Kind, content string, ruleSet []rules.Rule) []rules.Finding {
+	if !hasRules(kind) {
+		return nil
+	}
+	findings, err := checkRules(kind, content, ruleSet)
+	if err != nil {
+		return nil
+	}
+	return findings
+}
+
+// findingsList formats findings as a Markdown list
+func findingsList(findings []rules.Finding) string {
+	var b strings.Builder
+	for _, f := range findings {
+		fmt.Fprintf(&b, "- Line %d, %s (%s severity, `%s`): %s\n", f.Line, f.Resource, f.Severity, f.Rule, f.Message)
+	}
+	return b.String()
+}
+
+// findingsMarkdown returns a report section for the findings grouped by
+// focus area, or nothing when there are none
+func findingsMarkdown(findings []rules.Finding) string {
+	if len(findings) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("## Rule Findings\n\n")
+	for _, area := range focusAreas {
+		group := []rules.Finding{}
+		for _, f := range findings {
+			if f.Category == area.name {
+				group = append(group, f)
+			}
+		}
+		if len(group) > 0 {
+			fmt.Fprintf(&b, "### %s\n\n%s\n", areaTitle(area.name), findingsList(group))
+		}
+	}
+	return b.String()
+}
+
+// explainFindings asks the model to explain the findings and prints the
+// answer
+func explainFindings(kind filetype.Kind, content string, findings []rules.Finding) {
+	apiHost, apiKey, aiModel, err := openWebUISettings()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	language := "yaml"
+	if kind == filetype.Dockerfile {
+		language = "dockerfile"
+	}
+	manifest := fmt.Sprintf("```%s\n%s\n```", language, strings.TrimRight(content, "\n"))
+	prompt := fmt.Sprintf(explainFindingsPrompt, kind, findingsList(findings), manifest)
+	response, err := sendQueryToOpenWebUI(apiHost, apiKey, aiModel, []map[string]string{
+		{"role": "user", "content": prompt},
+	})
+	if err != nil {
+		fmt.Printf("Error from AI: %v\n", err)
+		os.Exit(1)
+	}
+
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(80),
+	)
+	if err != nil {
+		fmt.Printf("Error initializing renderer: %v\n", err)
+		os.Exit(1)
+	}
+	renderedOutput, err := renderer.Render(response)
+	if err != nil {
+		fmt.Printf("Error rendering markdown: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(renderedOutput)
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+	"github.com/spf13/viper"
+)
+
+// TestOptimizeFileFindings checks rule findings are reported and passed to
+// the model for Kubernetes manifests
+func TestOptimizeFileFindings(t *testing.T) {
+	var prompt string
+	server := newFakeModel(t, func(p string) string {
+		prompt = p
+		return "Pin the image."
+	})
+
+	path := filepath.Join(t.TempDir(), "deployment.yaml")
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n      - name: web\n        image: nginx:latest\n"
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := optimizeFile(server.URL, "key", "model", path, review{Rules: rules.Rules}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(response, "## Rule Findings") || !strings.HasSuffix(response, "Pin the image.") {
+		t.Errorf("expected the findings before the suggestions, got %q", response)
+	}
+	if !strings.Contains(prompt, findingsInstruction) || !strings.Contains(prompt, "Line 10, Deployment/web") {
+		t.Errorf("expected the findings in the prompt, got %q", prompt)
+	}
+}
+
+// TestOptimizeFileChunkedRevalidate checks code in the combined answer for a
+// large file is checked, and a fix is asked for when it does not parse
+func TestOptimizeFileChunkedRevalidate(t *testing.T) {
+	viper.Set("chunking.max_tokens", 20)
+	t.Cleanup(func() { viper.Set("chunking.max_tokens", 0) })
+
+	fixes := 0
+	server := newFakeModel(t, func(p string) string {
+		switch {
+		case strings.Contains(p, "contains code with errors"):
+			fixes++
+			return "```yaml\nreplicas: 2\n```"
+		case strings.Contains(p, "Original request"):
+			return "```yaml\nreplicas: [2\n```"
+		}
+		return "Set replicas."
+	})
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	config := "name: web\nreplicas: 1\nimage: nginx:1.27\n---\nname: worker\nreplicas: 1\nimage: busybox:1.36\n"
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := optimizeFile(server.URL, "key", "model", path, review{}, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fixes != 1 || !strings.Contains(response, "replicas: 2\n") {
+		t.Errorf("expected the combined answer to be fixed, got %d fixes and %q", fixes, response)
+	}
+}
+
+// TestLintFilesContinuesAfterFailure checks a file that cannot be read is
+// reported as failed and the other files are still linted
+func TestLintFilesContinuesAfterFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deployment.yaml")
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  template:\n    spec:\n      containers:\n      - name: web\n        image: nginx:latest\n"
+	if err := os.WriteFile(path, []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	total, failed := lintFiles([]string{filepath.Join(dir, "missing.yaml"), path}, &out)
+	if len(failed) != 1 || !strings.HasSuffix(failed[0].Name, "missing.yaml") {
+		t.Errorf("expected the missing file to fail, got %+v", failed)
+	}
+	if total == 0 || !strings.Contains(out.String(), "deployment.yaml: ") {
+		t.Errorf("expected the manifest to be linted after the failure, got %q", out.String())
+	}
+}
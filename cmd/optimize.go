@@ -6,70 +6,138 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/ruanbekker/devops-ai-cli/internal/chunk"
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
 	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/ruanbekker/devops-ai-cli/internal/redact"
+	"github.com/ruanbekker/devops-ai-cli/internal/report"
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+	"github.com/ruanbekker/devops-ai-cli/internal/validate"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"net/http"
 )
 
 var optimizeFilePath string
+var optimizeRecursive bool
+var optimizeConcurrency int
+var optimizeApply bool
+var optimizeYes bool
+var optimizePatchPath string
+var optimizeFormat string
+var optimizeFailOn string
+var optimizeOutputPath string
+var optimizeFocus []string
+var optimizeProfile string
 
 var optimizeCmd = &cobra.Command{
-	Use:   "optimize -f <file>",
+	Use:   "optimize -f <file> [instruction]",
 	Short: "Optimize a code or configuration file using AI",
 	Long: `Reads a code/configuration file (YAML, JSON, Python, Terraform, Shell, etc.)
-and sends it to OpenWebUI API for optimization. The AI returns suggestions in Markdown format.`,
-	Args: cobra.NoArgs,
+and sends it to OpenWebUI API for optimization. The AI returns suggestions in Markdown format.
+Use -f - or pipe the content on stdin to optimize piped input, e.g.
+  helm template . | devopscli optimize "focus on resource limits"
+Pass a directory (with --recursive for sub directories) or a glob to optimize
+every supported file concurrently and get one report grouped by file.
+Use --apply to get the complete revised file, review it as a diff and write it
+(keeping a .bak backup), or --output-patch to save the diff for git.
+Use --format json, sarif or junit for findings with severity, category, file
+and line, e.g. for CI, and --fail-on to exit with status 1 when a finding is at
+or above a severity.
+Use --focus security,cost to review only some areas, with the report grouped
+by area, or --profile for a review profile defined in config.yaml.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		// Read from stdin with -f - or when input is piped without -f
+		if optimizeFilePath == "" && stdinIsPiped() {
+			optimizeFilePath = "-"
+		}
+
 		// Ensure file path is provided
 		if optimizeFilePath == "" {
 			fmt.Println("Error: Please specify a file with -f")
 			os.Exit(1)
 		}
 
-		// Read API settings from config.yaml or environment variables
-		apiHost := viper.GetString("openwebui.host")
-		apiKey := viper.GetString("openwebui.api_key")
-		aiModel := viper.GetString("openwebui.model")
-
-		// If no API host in config, check environment variable
-		if apiHost == "" {
-			apiHost = os.Getenv("OPENWEB_API_HOST")
+		instruction := ""
+		if len(args) > 0 {
+			instruction = args[0]
 		}
 
-		// If no API key in config, check environment variable
-		if apiKey == "" {
-			apiKey = os.Getenv("OPENWEB_API_KEY")
+		// Shape the prompt and rules by focus area and review profile
+		r, err := newReview(instruction, optimizeFocus, optimizeProfile)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		// Exit if no API key is found
-		if apiHost == "" || apiKey == "" {
-			fmt.Println("Error: OpenWebUI host and API key must be set in config.yaml or environment variable OPENWEB_API_KEY")
+		var failOn rules.Severity
+		if optimizeFormat != "markdown" && !slices.Contains(report.Formats, optimizeFormat) {
+			fmt.Printf("Error: unknown format %q, use markdown, %s\n", optimizeFormat, strings.Join(report.Formats, ", "))
 			os.Exit(1)
 		}
+		if optimizeFailOn != "" {
+			if optimizeFormat == "markdown" {
+				fmt.Println("Error: --fail-on needs --format json, sarif or junit")
+				os.Exit(1)
+			}
+			severity, err := rules.ParseSeverity(optimizeFailOn)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			failOn = severity
+		}
 
-		// Read the file content
-		content, err := os.ReadFile(optimizeFilePath)
+		// Read API settings from config.yaml or environment variables
+		apiHost, apiKey, aiModel, err := openWebUISettings()
 		if err != nil {
-			fmt.Printf("Error reading file: %v\n", err)
+			fmt.Println("Error:", err)
 			os.Exit(1)
 		}
 
-		// Get the file extension (to provide context to AI)
-		fileType := detectFileType(optimizeFilePath)
+		// Ask for the revised file and show it as a diff
+		if optimizeApply || optimizePatchPath != "" {
+			if optimizeFilePath == "-" || isBatchTarget(optimizeFilePath) {
+				fmt.Println("Error: --apply and --output-patch need a single file with -f")
+				os.Exit(1)
+			}
+			runOptimizeApply(apiHost, apiKey, aiModel, optimizeFilePath, r)
+			return
+		}
 
-		// Log debug information
-		if viper.GetBool("debug") {
-			logger.Log(fmt.Sprintf("optimize: using %s model for %s", aiModel, fileType))
+		// Write structured findings, e.g. for code scanning in CI
+		if optimizeFormat != "markdown" {
+			runOptimizeFindings(apiHost, apiKey, aiModel, r, failOn)
+			return
 		}
 
-		// Send file content to OpenWebUI
-		markdownResponse, err := sendToOpenWebUI(apiHost, apiKey, aiModel, string(content), fileType)
-		if err != nil {
-			fmt.Printf("Error from AI: %v\n", err)
-			os.Exit(1)
+		var markdownResponse string
+		var failed []batchResult
+		if optimizeFilePath != "-" && isBatchTarget(optimizeFilePath) {
+			// Optimize every supported file in a directory or glob
+			files, err := discoverFiles(optimizeFilePath, optimizeRecursive)
+			if err != nil {
+				fmt.Printf("Error finding files: %v\n", err)
+				os.Exit(1)
+			}
+
+			workers := batchConcurrency(optimizeConcurrency)
+			fmt.Fprintf(os.Stderr, "🚀 Optimizing %d file(s) with %d worker(s)\n", len(files), workers)
+			results := processFiles(files, workers, os.Stderr, func(path string) (string, error) {
+				return optimizeFile(apiHost, apiKey, aiModel, path, r, 1)
+			})
+			markdownResponse, failed = batchReport(results)
+		} else {
+			response, err := optimizeFile(apiHost, apiKey, aiModel, optimizeFilePath, r, batchConcurrency(optimizeConcurrency))
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			markdownResponse = response
 		}
 
 		// Render Markdown using Glamour
@@ -89,51 +157,180 @@ and sends it to OpenWebUI API for optimization. The AI returns suggestions in Ma
 		}
 
 		fmt.Println(renderedOutput)
+
+		if len(failed) > 0 {
+			fmt.Printf("⚠️ %d file(s) failed:\n", len(failed))
+			for _, result := range failed {
+				fmt.Printf("  - %s: %v\n", result.Name, result.Err)
+			}
+			os.Exit(1)
+		}
 	},
 }
 
 func init() {
 	// Add flag for file input
-	optimizeCmd.Flags().StringVarP(&optimizeFilePath, "file", "f", "", "Path to the file to optimize")
+	optimizeCmd.Flags().StringVarP(&optimizeFilePath, "file", "f", "", "Path to the file, directory or glob to optimize, or - for stdin")
+	optimizeCmd.Flags().BoolVarP(&optimizeRecursive, "recursive", "r", false, "Search sub directories when -f is a directory")
+	optimizeCmd.Flags().IntVarP(&optimizeConcurrency, "concurrency", "j", 0, "Number of files to optimize at once (default optimize.concurrency or 4)")
+	optimizeCmd.Flags().BoolVar(&optimizeApply, "apply", false, "Ask for the revised file, show a diff and write it after confirmation")
+	optimizeCmd.Flags().BoolVarP(&optimizeYes, "yes", "y", false, "Apply changes without asking for confirmation")
+	optimizeCmd.Flags().StringVar(&optimizePatchPath, "output-patch", "", "Write the changes as a unified diff to this file")
+	optimizeCmd.Flags().StringVar(&optimizeFormat, "format", "markdown", "Output format: markdown, json, sarif or junit")
+	optimizeCmd.Flags().StringVar(&optimizeFailOn, "fail-on", "", "Exit with status 1 when a finding is at or above this severity: low, medium or high")
+	optimizeCmd.Flags().StringVarP(&optimizeOutputPath, "output", "o", "", "Write the findings to this file instead of stdout")
+	optimizeCmd.Flags().StringSliceVar(&optimizeFocus, "focus", nil, "Focus areas: security, reliability, performance, cost, readability (comma separated)")
+	optimizeCmd.Flags().StringVar(&optimizeProfile, "profile", "", "Review profile from optimize.profiles in config.yaml")
 	rootCmd.AddCommand(optimizeCmd)
 }
 
-// detectFileType returns a file type based on the extension
-func detectFileType(filePath string) string {
-	switch {
-	case hasExtension(filePath, ".yaml", ".yml"):
-		return "Kubernetes YAML"
-	case hasExtension(filePath, ".json"):
-		return "JSON configuration"
-	case hasExtension(filePath, ".tf"):
-		return "Terraform script"
-	case hasExtension(filePath, ".sh"):
-		return "Shell script"
-	case hasExtension(filePath, ".py"):
-		return "Python script"
-	default:
-		return "Unknown format"
+// runOptimizeApply asks for the revised file and reviews the changes
+func runOptimizeApply(apiHost, apiKey, model, path string, r review) {
+	content, err := readOptimizeInput(path)
+	if err != nil {
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
 	}
+
+	kind := filetype.Detect(path, content)
+	logger.Log(fmt.Sprintf("optimize: asking %s model for a revised %s", model, kind))
+
+	revised, err := reviseFile(apiHost, apiKey, model, content, kind, r.prompt())
+	if err != nil {
+		fmt.Printf("Error from AI: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := reviewChanges(path, content, revised, optimizePatchPath, optimizeApply, optimizeYes, os.Stdin, os.Stdout); err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// findingsInstruction introduces the rule findings in the optimize prompt
+const findingsInstruction = "A linter found these problems. Explain each one and include its fix in your suggestions:"
+
+// optimizeHints focus the prompt on what matters most for each kind of file
+var optimizeHints = map[filetype.Kind]string{
+	filetype.Kubernetes:     "Check resource requests and limits, liveness and readiness probes, security context, image tags and labels.",
+	filetype.HelmChart:      "Check chart metadata, versioning and dependency version constraints.",
+	filetype.HelmValues:     "Check the defaults for resources, image tags and security settings.",
+	filetype.DockerCompose:  "Check image pinning, healthchecks, restart policies, resource limits and how secrets are passed.",
+	filetype.GitHubActions:  "Check action version pinning, token permissions, caching, secrets usage and concurrency.",
+	filetype.Ansible:        "Check idempotency, fully qualified module names, become usage and handlers.",
+	filetype.CloudFormation: "Check IAM least privilege, encryption, deletion policies and parameters.",
+	filetype.Terraform:      "Check provider and module version pinning, variables, state safety and security settings.",
+	filetype.HCL:            "Check structure, version pinning and repeated configuration.",
+	filetype.Dockerfile:     "Check base image pinning, layer order for caching, multi-stage builds, package cache cleanup and running as a non-root user.",
+	filetype.Jenkinsfile:    "Check stage structure, credentials handling, parallel stages and agent usage.",
+	filetype.Makefile:       "Check .PHONY targets, variables, portability and error handling.",
+	filetype.Shell:          "Check quoting, error handling such as set -euo pipefail, and portability.",
+	filetype.Python:         "Check error handling, readability and performance.",
 }
 
-// hasExtension checks if the file has one of the provided extensions
-func hasExtension(filePath string, extensions ...string) bool {
-	for _, ext := range extensions {
-		if len(filePath) > len(ext) && filePath[len(filePath)-len(ext):] == ext {
-			return true
+// optimizeFile reads a file and returns the suggestions for it. Files above
+// chunking.max_tokens are optimized in chunks using workers at once.
+func optimizeFile(apiHost, apiKey, model, path string, r review, workers int) (string, error) {
+	content, err := readOptimizeInput(path)
+	if err != nil {
+		return "", fmt.Errorf("reading file: %w", err)
+	}
+
+	name := path
+	if path == "-" {
+		name = "stdin"
+	}
+
+	// Detect the kind of file from its name and content
+	kind := filetype.Detect(path, content)
+	logger.Log(fmt.Sprintf("optimize: using %s model for %s (%s)", model, name, kind))
+
+	// Rule findings are always reported and passed on for the model to
+	// explain and fix, rather than relying on the model to spot them
+	instruction := r.prompt()
+	findings := localFindings(kind, content, r.Rules)
+	if len(findings) > 0 {
+		instruction = strings.TrimSpace(instruction + "\n\n" + findingsInstruction + "\n" + findingsList(findings))
+	}
+
+	// One redactor for the whole file, so a secret has the same placeholder
+	// in every chunk and in the combined answer
+	redactor := newRedactor()
+	var response, prompt string
+	if chunks := chunk.Split(content, chunkKind(kind, content), maxChunkTokens()); len(chunks) > 1 {
+		request := "Optimize this " + kind.String()
+		if instruction != "" {
+			request = instruction
 		}
+		response, err = mapReduce(apiHost, apiKey, model, redactor, chunks, name, kind.String(), request, workers, os.Stderr, func(c chunk.Chunk, part string) (string, error) {
+			return sendToOpenWebUI(apiHost, apiKey, model, redactor, c.Content, kind, part, instruction)
+		})
+		if err != nil {
+			return "", err
+		}
+		// The whole file does not fit in a request, so a fix is asked for
+		// with the original request only
+		prompt = request
+	} else {
+		response, err = sendToOpenWebUI(apiHost, apiKey, model, redactor, content, kind, "", instruction)
+		if err != nil {
+			return "", fmt.Errorf("from AI: %w", err)
+		}
+
+		prompt = optimizePrompt(content, kind, "", instruction)
+	}
+
+	// Ask again when code in the suggestions does not parse
+	if checked, err := revalidate(apiHost, apiKey, model, redactor, prompt, response, validate.CodeBlocks); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ %s: %v\n", name, err)
+	} else {
+		response = checked
 	}
-	return false
+	return findingsMarkdown(findings) + response, nil
 }
 
-// sendToOpenWebUI sends the file content to OpenWebUI API and returns Markdown suggestions
-func sendToOpenWebUI(apiHost, apiKey, model, content, fileType string) (string, error) {
-	// Construct API request payload
+// readOptimizeInput reads the file to optimize, or stdin when path is -
+func readOptimizeInput(path string) (string, error) {
+	if path == "-" {
+		return readLimited(os.Stdin, "stdin")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	return readLimited(file, path)
+}
+
+// optimizePrompt builds the prompt asking for suggestions for content
+func optimizePrompt(content string, kind filetype.Kind, part, instruction string) string {
+	description := kind.String()
+	if part != "" {
+		description = fmt.Sprintf("%s (%s)", description, part)
+	}
+	prompt := fmt.Sprintf("Optimize this %s:\n\n%s", description, content)
+	if hint := optimizeHints[kind]; hint != "" {
+		prompt = fmt.Sprintf("Optimize this %s. %s\n\n%s", description, hint, content)
+	}
+	if instruction != "" {
+		prompt = instruction + "\n\n" + prompt
+	}
+	return prompt
+}
+
+// sendToOpenWebUI sends the file content to OpenWebUI API and returns Markdown suggestions.
+// part describes the chunk when only part of a file is sent. An optional instruction
+// is sent ahead of the file content.
+func sendToOpenWebUI(apiHost, apiKey, model string, redactor *redact.Redactor, content string, kind filetype.Kind, part, instruction string) (string, error) {
+	prompt := optimizePrompt(content, kind, part, instruction)
+
+	// Construct API request payload with secrets redacted
 	requestBody, err := json.Marshal(map[string]interface{}{
 		"model": model,
-		"messages": []map[string]string{
-			{"role": "user", "content": fmt.Sprintf("Optimize this %s:\n\n%s", fileType, content)},
-		},
+		"messages": redactMessages(redactor, []map[string]string{
+			{"role": "user", "content": prompt},
+		}),
 	})
 	if err != nil {
 		return "", err
@@ -181,6 +378,6 @@ func sendToOpenWebUI(apiHost, apiKey, model, content, fileType string) (string,
 	}
 
 	// Extract the AI-generated Markdown response
-	return jsonResponse.Choices[0].Message.Content, nil
+	return restoreResponse(redactor, jsonResponse.Choices[0].Message.Content), nil
 }
 
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/ruanbekker/devops-ai-cli/internal/plan"
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+	"github.com/spf13/cobra"
+)
+
+var planReviewFilePath string
+var planReviewNoSummary bool
+var planReviewFailOn string
+
+const planReviewPrompt = `You are reviewing a Terraform plan before it is applied. These are the
+changes and the risks a checker found:
+
+%s
+
+Write a concise summary for the reviewer in Markdown: what the plan does in a
+few sentences, the changes that could cause downtime or data loss, and what to
+verify before applying. Do not repeat the full list of changes.`
+
+var planReviewCmd = &cobra.Command{
+	Use:   "plan-review -f <plan.json>",
+	Short: "Summarize the changes and risks of a Terraform plan",
+	Long: `Reads the JSON output of terraform show -json and lists the resources that are
+created, updated, replaced and destroyed, with the attributes that change.
+Risky changes are flagged without calling the model: databases that are
+destroyed or replaced, security groups and firewall rules opened to 0.0.0.0/0
+and IAM changes. The model then writes a short summary for the reviewer, based
+on the list of changes only, so attribute values are never sent.
+
+  terraform plan -out tfplan
+  terraform show -json tfplan > plan.json
+  devopscli plan-review -f plan.json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read from stdin with -f - or when input is piped without -f
+		if planReviewFilePath == "" && stdinIsPiped() {
+			planReviewFilePath = "-"
+		}
+		if planReviewFilePath == "" {
+			fmt.Println("Error: Please specify a plan with -f")
+			os.Exit(1)
+		}
+
+		var failOn rules.Severity
+		if planReviewFailOn != "" {
+			severity, err := rules.ParseSeverity(planReviewFailOn)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			failOn = severity
+		}
+
+		// Plans are often larger than input.max_size, and only the list of
+		// changes is sent to the model
+		var data []byte
+		var err error
+		if planReviewFilePath == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(planReviewFilePath)
+		}
+		if err != nil {
+			fmt.Printf("Error reading plan: %v\n", err)
+			os.Exit(1)
+		}
+
+		p, err := plan.Parse(data)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		risks := plan.Risks(p)
+		markdown := planMarkdown(p, risks)
+
+		if !planReviewNoSummary && len(p.Changes) > 0 {
+			summary, err := planSummary(markdown)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️ No reviewer summary: %v\n", err)
+			} else {
+				markdown += "\n## Reviewer Summary\n\n" + summary + "\n"
+			}
+		}
+
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(80),
+		)
+		if err != nil {
+			fmt.Printf("Error initializing renderer: %v\n", err)
+			os.Exit(1)
+		}
+		renderedOutput, err := renderer.Render(markdown)
+		if err != nil {
+			fmt.Printf("Error rendering markdown: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(renderedOutput)
+
+		if failOn != 0 {
+			for _, risk := range risks {
+				if risk.Severity >= failOn {
+					fmt.Fprintf(os.Stderr, "❌ The plan has risks at or above %s severity\n", failOn)
+					os.Exit(1)
+				}
+			}
+		}
+	},
+}
+
+func init() {
+	planReviewCmd.Flags().StringVarP(&planReviewFilePath, "file", "f", "", "Plan from terraform show -json, or - for stdin")
+	planReviewCmd.Flags().BoolVar(&planReviewNoSummary, "no-summary", false, "Only list the changes and risks, without asking the model")
+	planReviewCmd.Flags().StringVar(&planReviewFailOn, "fail-on", "", "Exit with status 1 when a risk is at or above this severity: low, medium or high")
+	rootCmd.AddCommand(planReviewCmd)
+}
+
+// planMarkdown lists the risks and the changes of a plan grouped by action
+func planMarkdown(p plan.Plan, risks []plan.Risk) string {
+	var b strings.Builder
+	b.WriteString("# Terraform Plan Review\n\n")
+	if len(p.Changes) == 0 {
+		b.WriteString("No changes, the infrastructure matches the configuration.\n")
+		return b.String()
+	}
+
+	counts := []string{}
+	for _, action := range plan.Actions {
+		counts = append(counts, fmt.Sprintf("**%d** to %s", p.Count(action), action))
+	}
+	fmt.Fprintf(&b, "Plan: %s\n\n", strings.Join(counts, ", "))
+
+	if len(risks) > 0 {
+		b.WriteString("## ⚠️ Risks\n\n")
+		for _, risk := range risks {
+			fmt.Fprintf(&b, "- **%s** `%s`: %s\n", risk.Severity, risk.Address, risk.Message)
+		}
+		b.WriteString("\n")
+	}
+
+	for _, action := range plan.Actions {
+		if p.Count(action) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "## %s\n\n", areaTitle(string(action)))
+		for _, c := range p.Changes {
+			if c.Action != action {
+				continue
+			}
+			if len(c.Attributes) > 0 {
+				fmt.Fprintf(&b, "- `%s`: %s\n", c.Address, strings.Join(c.Attributes, ", "))
+			} else {
+				fmt.Fprintf(&b, "- `%s`\n", c.Address)
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// planSummary asks the model for a reviewer summary of the listed changes
+func planSummary(changes string) (string, error) {
+	apiHost, apiKey, aiModel, err := openWebUISettings()
+	if err != nil {
+		return "", err
+	}
+	logger.Log(fmt.Sprintf("plan-review: asking %s model for a reviewer summary", aiModel))
+
+	response, err := sendQueryToOpenWebUI(apiHost, apiKey, aiModel, []map[string]string{
+		{"role": "user", "content": fmt.Sprintf(planReviewPrompt, changes)},
+	})
+	if err != nil {
+		return "", fmt.Errorf("from AI: %w", err)
+	}
+	return strings.TrimSpace(response), nil
+}
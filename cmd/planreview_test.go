@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/plan"
+	"github.com/spf13/viper"
+)
+
+const reviewPlan = `{
+  "format_version": "1.2",
+  "resource_changes": [
+    {"address": "aws_rds_cluster.main", "mode": "managed", "type": "aws_rds_cluster",
+     "change": {"actions": ["delete"], "before": {"master_password": "hunter2"}, "after": null}},
+    {"address": "aws_instance.web", "mode": "managed", "type": "aws_instance",
+     "change": {"actions": ["update"], "before": {"instance_type": "t3.micro"}, "after": {"instance_type": "t3.small"}}}
+  ]
+}`
+
+// TestPlanMarkdown checks the counts, risks and changes are listed
+func TestPlanMarkdown(t *testing.T) {
+	p, err := plan.Parse([]byte(reviewPlan))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	markdown := planMarkdown(p, plan.Risks(p))
+
+	for _, want := range []string{
+		"**0** to create, **1** to update, **0** to replace, **1** to destroy",
+		"- **high** `aws_rds_cluster.main`: database is destroyed",
+		"## Update\n\n- `aws_instance.web`: instance_type\n",
+		"## Destroy\n\n- `aws_rds_cluster.main`\n",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("expected %q in %q", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "## Create") {
+		t.Errorf("expected no section for actions without changes")
+	}
+}
+
+// TestPlanSummary checks the model only gets the list of changes, not the
+// attribute values
+func TestPlanSummary(t *testing.T) {
+	var prompt string
+	server := newFakeModel(t, func(p string) string {
+		prompt = p
+		return "The plan destroys the database.\n"
+	})
+
+	viper.Set("openwebui.host", server.URL)
+	viper.Set("openwebui.api_key", "key")
+	t.Cleanup(func() {
+		viper.Set("openwebui.host", "")
+		viper.Set("openwebui.api_key", "")
+	})
+
+	p, _ := plan.Parse([]byte(reviewPlan))
+	summary, err := planSummary(planMarkdown(p, plan.Risks(p)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != "The plan destroys the database." {
+		t.Errorf("unexpected summary %q", summary)
+	}
+	if !strings.Contains(prompt, "aws_rds_cluster.main") || strings.Contains(prompt, "hunter2") {
+		t.Errorf("unexpected prompt %q", prompt)
+	}
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// currentProject returns the root of the git repository containing the
+// working directory, or the working directory itself outside a repository
+func currentProject() string {
+	out, err := exec.Command("git", "rev-parse", "--show-toplevel").Output()
+	if err == nil {
+		if root := strings.TrimSpace(string(out)); root != "" {
+			return filepath.Clean(root)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	return filepath.Clean(wd)
+}
+
+// projectName returns a short display name for a project path
+func projectName(project string) string {
+	if project == "" {
+		return "no project"
+	}
+	return filepath.Base(project)
+}
+
+// conversationsForProject returns the conversations started in a project.
+// Conversations without a project, such as those saved before projects were
+// recorded or pulled from OpenWebUI, are listed in every project.
+func conversationsForProject(list []Conversation, project string) []Conversation {
+	result := []Conversation{}
+	for _, conv := range list {
+		if conv.Project == project || conv.Project == "" {
+			result = append(result, conv)
+		}
+	}
+	return result
+}
+
+// mergeTags adds new tags to existing ones, skipping blanks and duplicates
+func mergeTags(existing, tags []string) []string {
+	result := append([]string{}, existing...)
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag != "" && !hasTag(result, tag) {
+			result = append(result, tag)
+		}
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
@@ -1,17 +1,23 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/glamour"
+	"github.com/ruanbekker/devops-ai-cli/internal/encryption"
 	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/ruanbekker/devops-ai-cli/internal/redact"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -24,12 +30,32 @@ var conversationID string
 var listConversations bool
 var clearConversations bool
 var deleteConversationID int
+var forkConversationID int
+var forkAtTurn int
+var retryConversationID int
+var editConversationID int
+var undoConversationID int
+var queryModel string
+var queryTags []string
+var listAllConversations bool
+var queryAttachments []string
 
 // Structs for conversation storage
 type Conversation struct {
-	ID      int                 `json:"id"`
-	History []map[string]string `json:"history"`
-	Query   string              `json:"query"`
+	ID          int                 `json:"id"`
+	History     []map[string]string `json:"history"`
+	Query       string              `json:"query"`
+	Model       string              `json:"model,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Project     string              `json:"project,omitempty"`
+	Pinned      bool                `json:"pinned,omitempty"`
+	Summary     string              `json:"summary,omitempty"`
+	Attachments []string            `json:"attachments,omitempty"`
+	RemoteID    string              `json:"remote_id,omitempty"`
+	ParentID    int                 `json:"parent_id,omitempty"`
+	ForkedAt    int                 `json:"forked_at,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   time.Time           `json:"updated_at"`
 }
 
 type Conversations struct {
@@ -40,7 +66,10 @@ var queryCmd = &cobra.Command{
 	Use:   "query <message>",
 	Short: "Ask OpenWebUI a question and maintain conversation context",
 	Long: `Send a question to OpenWebUI and get a response.
-Use --cid "<conversation-id>" to continue a previous conversation.`,
+Use --cid "<conversation-id>" to continue a previous conversation.
+Input piped on stdin is sent along with the message.
+Use --fork "<conversation-id>" --at <turn> to branch a conversation from an earlier turn.
+Use --retry, --edit or --undo "<conversation-id>" to rework the last turn of a conversation.`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		// Handle --clear flag (delete all conversations)
@@ -67,44 +96,129 @@ Use --cid "<conversation-id>" to continue a previous conversation.`,
 
 		// Handle --list flag (show all conversations)
 		if listConversations {
-			listStoredConversations()
+			listStoredConversations(listAllConversations)
 			return
 		}
 
-		// Ensure query message is provided
-		if len(args) == 0 {
-			fmt.Println("Error: Please provide a query or use --list, --clear, or --delete.")
-			os.Exit(1)
+		// Handle --fork flag (branch a conversation, optionally continuing it)
+		if forkConversationID > 0 {
+			fork, err := forkConversation(forkConversationID, forkAtTurn)
+			if err != nil {
+				fmt.Printf("Error forking conversation ID %d: %v\n", forkConversationID, err)
+				os.Exit(1)
+			}
+			fmt.Printf("🍴 Forked conversation ID %d at turn %d into conversation ID %d\n", forkConversationID, fork.ForkedAt, fork.ID)
+			if len(args) == 0 {
+				return
+			}
+			conversationID = strconv.Itoa(fork.ID)
 		}
 
-		message := args[0]
-
-		// Read API settings
-		apiHost := viper.GetString("openwebui.host")
-		apiKey := viper.GetString("openwebui.api_key")
-		aiModel := viper.GetString("openwebui.model")
-
-		if apiHost == "" {
-			apiHost = os.Getenv("OPENWEB_API_HOST")
-		}
-		if apiKey == "" {
-			apiKey = os.Getenv("OPENWEB_API_KEY")
+		// Handle --undo flag (drop the last exchange of a conversation)
+		if undoConversationID > 0 {
+			err := undoLastExchange(undoConversationID)
+			if err != nil {
+				fmt.Printf("Error undoing last exchange of conversation ID %d: %v\n", undoConversationID, err)
+				os.Exit(1)
+			}
+			fmt.Printf("↩️ Removed the last exchange from conversation ID %d\n", undoConversationID)
+			return
 		}
 
-		if apiHost == "" || apiKey == "" {
-			fmt.Println("Error: OpenWebUI host and API key must be set in config.yaml or environment variables.")
+		// Read API settings
+		apiHost, apiKey, aiModel, err := openWebUISettings()
+		if err != nil {
+			fmt.Println("Error:", err)
 			os.Exit(1)
 		}
+		if queryModel != "" {
+			aiModel = queryModel
+		}
 
-		// Load conversation history if --cid is used
 		history := []map[string]string{}
 		conversationNumber := 0
-		if conversationID != "" {
+		message := ""
+		title := ""
+		attachedFiles := []string{}
+
+		switch {
+		// Handle --retry flag (regenerate the last assistant reply)
+		case retryConversationID > 0:
+			conversationID = strconv.Itoa(retryConversationID)
 			history, conversationNumber = loadConversationByID(conversationID)
-		}
+			if conversationNumber == 0 {
+				fmt.Printf("Error: conversation ID %d not found\n", retryConversationID)
+				os.Exit(1)
+			}
+			var err error
+			history, err = historyForRetry(history)
+			if err != nil {
+				fmt.Printf("Error retrying conversation ID %d: %v\n", retryConversationID, err)
+				os.Exit(1)
+			}
+			message = history[len(history)-1]["content"]
 
-		// Append new user query
-		history = append(history, map[string]string{"role": "user", "content": message})
+		// Handle --edit flag (edit the last user message and resend it)
+		case editConversationID > 0:
+			conversationID = strconv.Itoa(editConversationID)
+			history, conversationNumber = loadConversationByID(conversationID)
+			if conversationNumber == 0 {
+				fmt.Printf("Error: conversation ID %d not found\n", editConversationID)
+				os.Exit(1)
+			}
+			var err error
+			history, err = historyForRetry(history)
+			if err != nil {
+				fmt.Printf("Error editing conversation ID %d: %v\n", editConversationID, err)
+				os.Exit(1)
+			}
+			message, err = editInEditor(history[len(history)-1]["content"])
+			if err != nil {
+				fmt.Printf("Error editing message: %v\n", err)
+				os.Exit(1)
+			}
+			if message == "" {
+				fmt.Println("Edited message is empty, nothing was sent.")
+				return
+			}
+			history[len(history)-1]["content"] = message
+
+		default:
+			// Read piped input, using the argument as the instruction
+			input, err := readStdin()
+			if err != nil {
+				fmt.Printf("Error reading stdin: %v\n", err)
+				os.Exit(1)
+			}
+
+			// Ensure query message is provided
+			if len(args) == 0 && input == "" && len(queryAttachments) == 0 {
+				fmt.Println("Error: Please provide a query or use --list, --clear, --delete, --fork, --retry, --edit or --undo.")
+				os.Exit(1)
+			}
+			title = "Explain the following input."
+			if len(args) > 0 {
+				title = args[0]
+			}
+			message = combineInput(title, input)
+
+			// Embed attached files into the message
+			attachments, err := loadAttachments(queryAttachments)
+			if err != nil {
+				fmt.Printf("Error reading attachments: %v\n", err)
+				os.Exit(1)
+			}
+			message = withAttachments(message, attachments)
+			attachedFiles = attachmentNames(attachments)
+
+			// Load conversation history if --cid is used
+			if conversationID != "" {
+				history, conversationNumber = loadConversationByID(conversationID)
+			}
+
+			// Append new user query
+			history = append(history, map[string]string{"role": "user", "content": message})
+		}
 
 		// Debug log
 		if viper.GetBool("debug") {
@@ -122,7 +236,7 @@ Use --cid "<conversation-id>" to continue a previous conversation.`,
 		history = append(history, map[string]string{"role": "assistant", "content": response})
 
 		// Save updated conversation history
-		newCID := saveConversation(history, conversationNumber, message)
+		newCID := saveConversation(history, conversationNumber, title, aiModel, queryTags, attachedFiles)
 
 		// Render Markdown response
 		renderer, err := glamour.NewTermRenderer(
@@ -147,9 +261,18 @@ Use --cid "<conversation-id>" to continue a previous conversation.`,
 
 func init() {
 	queryCmd.Flags().StringVarP(&conversationID, "cid", "c", "", "Continue a conversation with a conversation ID")
-	queryCmd.Flags().BoolVarP(&listConversations, "list", "l", false, "List previous conversations")
+	queryCmd.Flags().BoolVarP(&listConversations, "list", "l", false, "List previous conversations of the current project")
+	queryCmd.Flags().BoolVarP(&listAllConversations, "all", "a", false, "List conversations of all projects when used with --list")
+	queryCmd.Flags().StringSliceVarP(&queryTags, "tag", "t", nil, "Tag the conversation (repeatable or comma separated)")
+	queryCmd.Flags().StringArrayVar(&queryAttachments, "attach", nil, "Attach a file or glob as context (repeatable)")
 	queryCmd.Flags().BoolVarP(&clearConversations, "clear", "", false, "Delete all stored conversations")
 	queryCmd.Flags().IntVarP(&deleteConversationID, "delete", "d", 0, "Delete a specific conversation ID")
+	queryCmd.Flags().IntVar(&forkConversationID, "fork", 0, "Fork a conversation ID into a new conversation")
+	queryCmd.Flags().IntVar(&forkAtTurn, "at", 0, "Turn to fork at when using --fork (defaults to the last turn)")
+	queryCmd.Flags().IntVar(&retryConversationID, "retry", 0, "Regenerate the last reply of a conversation ID")
+	queryCmd.Flags().IntVar(&editConversationID, "edit", 0, "Edit the last message of a conversation ID in $EDITOR and resend it")
+	queryCmd.Flags().IntVar(&undoConversationID, "undo", 0, "Remove the last exchange from a conversation ID")
+	queryCmd.Flags().StringVarP(&queryModel, "model", "m", "", "Model to use instead of openwebui.model")
 	rootCmd.AddCommand(queryCmd)
 }
 
@@ -170,26 +293,264 @@ func deleteSingleConversation(id int) error {
 	}
 
 	conversations.List = newList
-	jsonData, err := json.MarshalIndent(conversations, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(sessionFile, jsonData, 0644)
+	return writeConversations(conversations)
 }
 
-// listStoredConversations lists all stored conversations
-func listStoredConversations() {
+// listStoredConversations lists the conversations of the current project,
+// or of every project when all is set, with forks shown underneath the
+// conversation they were branched from
+func listStoredConversations(all bool) {
 	conversations := loadAllConversations()
 	if len(conversations.List) == 0 {
 		fmt.Println("No previous conversations found.")
 		return
 	}
 
-	fmt.Println("\n📝 **Previous Conversations:**")
+	list := conversations.List
+	project := currentProject()
+	if !all {
+		list = conversationsForProject(conversations.List, project)
+		if len(list) == 0 {
+			fmt.Printf("No conversations found for %s, use --all to list every project.\n", project)
+			return
+		}
+	}
+
+	known := map[int]bool{}
+	for _, conv := range list {
+		known[conv.ID] = true
+	}
+
+	children := map[int][]Conversation{}
+	roots := []Conversation{}
+	for _, conv := range list {
+		if conv.ParentID != 0 && known[conv.ParentID] {
+			children[conv.ParentID] = append(children[conv.ParentID], conv)
+		} else {
+			roots = append(roots, conv)
+		}
+	}
+
+	if all {
+		fmt.Println("\n📝 **Previous Conversations (all projects):**")
+	} else {
+		fmt.Printf("\n📝 **Previous Conversations (%s):**\n", projectName(project))
+	}
+	for _, conv := range roots {
+		printConversationTree(conv, children, "", all)
+	}
+
+	if hidden := len(conversations.List) - len(list); hidden > 0 {
+		fmt.Printf("\n%d conversation(s) from other projects hidden, use --all to show them.\n", hidden)
+	}
+}
+
+// printConversationTree prints a conversation and its forks recursively
+func printConversationTree(conv Conversation, children map[int][]Conversation, indent string, showProject bool) {
+	details := ""
+	if conv.Pinned {
+		details += " 📌"
+	}
+	if len(conv.Tags) > 0 {
+		details += " [" + strings.Join(conv.Tags, ", ") + "]"
+	}
+	if showProject && conv.Project != "" {
+		details += " (" + projectName(conv.Project) + ")"
+	}
+
+	if conv.ParentID != 0 {
+		fmt.Printf("%s└─ 🍴 %d: %s (from %d @ turn %d)%s\n", indent, conv.ID, conv.Query, conv.ParentID, conv.ForkedAt, details)
+	} else {
+		fmt.Printf("%s🆔 %d: %s%s\n", indent, conv.ID, conv.Query, details)
+	}
+	for _, child := range children[conv.ID] {
+		printConversationTree(child, children, indent+"   ", showProject)
+	}
+}
+
+// countTurns returns the number of user messages in a history
+func countTurns(history []map[string]string) int {
+	turns := 0
+	for _, msg := range history {
+		if msg["role"] == "user" {
+			turns++
+		}
+	}
+	return turns
+}
+
+// historyUpToTurn returns a copy of the history up to and including the
+// given turn, where a turn is a user message and the replies that follow it
+func historyUpToTurn(history []map[string]string, turn int) ([]map[string]string, error) {
+	total := countTurns(history)
+	if turn < 1 || turn > total {
+		return nil, fmt.Errorf("turn %d out of range, conversation has %d turn(s)", turn, total)
+	}
+
+	result := []map[string]string{}
+	seen := 0
+	for _, msg := range history {
+		if msg["role"] == "user" {
+			seen++
+			if seen > turn {
+				break
+			}
+		}
+		copied := map[string]string{}
+		for k, v := range msg {
+			copied[k] = v
+		}
+		result = append(result, copied)
+	}
+	return result, nil
+}
+
+// forkConversation copies a conversation up to a turn into a new
+// conversation that references its parent. A turn of 0 copies every turn.
+func forkConversation(parentID, turn int) (Conversation, error) {
+	conversations := loadAllConversations()
+
+	var parent *Conversation
+	for i := range conversations.List {
+		if conversations.List[i].ID == parentID {
+			parent = &conversations.List[i]
+			break
+		}
+	}
+	if parent == nil {
+		return Conversation{}, fmt.Errorf("conversation not found")
+	}
+
+	if turn == 0 {
+		turn = countTurns(parent.History)
+	}
+	history, err := historyUpToTurn(parent.History, turn)
+	if err != nil {
+		return Conversation{}, err
+	}
+
+	now := time.Now()
+	fork := Conversation{
+		ID:        nextConversationID(conversations),
+		History:   history,
+		Query:     parent.Query,
+		Model:     parent.Model,
+		Tags:      append([]string{}, parent.Tags...),
+		Project:   parent.Project,
+		ParentID:  parent.ID,
+		ForkedAt:  turn,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	conversations.List = append(conversations.List, fork)
+
+	if err := writeConversations(conversations); err != nil {
+		return Conversation{}, err
+	}
+	return fork, nil
+}
+
+// lastUserIndex returns the index of the last user message, or -1
+func lastUserIndex(history []map[string]string) int {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i]["role"] == "user" {
+			return i
+		}
+	}
+	return -1
+}
+
+// historyForRetry returns the history up to and including the last user
+// message, dropping the replies that followed it
+func historyForRetry(history []map[string]string) ([]map[string]string, error) {
+	idx := lastUserIndex(history)
+	if idx < 0 {
+		return nil, fmt.Errorf("conversation has no user message")
+	}
+	return history[:idx+1], nil
+}
+
+// dropLastExchange returns the history without the last user message and
+// the replies that followed it
+func dropLastExchange(history []map[string]string) ([]map[string]string, error) {
+	idx := lastUserIndex(history)
+	if idx < 0 {
+		return nil, fmt.Errorf("conversation has no user message")
+	}
+	if idx == 0 {
+		return nil, fmt.Errorf("conversation only has one exchange, use --delete to remove it")
+	}
+	return history[:idx], nil
+}
+
+// undoLastExchange removes the last exchange from a stored conversation
+func undoLastExchange(id int) error {
+	conversations := loadAllConversations()
+	for i, conv := range conversations.List {
+		if conv.ID != id {
+			continue
+		}
+		history, err := dropLastExchange(conv.History)
+		if err != nil {
+			return err
+		}
+		conversations.List[i].History = history
+		conversations.List[i].UpdatedAt = time.Now()
+		return writeConversations(conversations)
+	}
+	return fmt.Errorf("conversation not found")
+}
+
+// editInEditor opens text in $EDITOR (falling back to vi) and returns the
+// edited result
+func editInEditor(text string) (string, error) {
+	editor := editorCommand()
+
+	tmpFile, err := os.CreateTemp("", "devopscli-*.md")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if _, err := tmpFile.WriteString(text); err != nil {
+		tmpFile.Close()
+		return "", err
+	}
+	tmpFile.Close()
+
+	editorCmd := exec.Command(editor[0], append(editor[1:], tmpFile.Name())...)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return "", fmt.Errorf("running %s: %w", editor[0], err)
+	}
+
+	edited, err := os.ReadFile(tmpFile.Name())
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(edited)), nil
+}
+
+// editorCommand splits $EDITOR into the command and its arguments, e.g.
+// "code --wait", falling back to vi when it is unset or blank
+func editorCommand() []string {
+	if parts := strings.Fields(os.Getenv("EDITOR")); len(parts) > 0 {
+		return parts
+	}
+	return []string{"vi"}
+}
+
+// nextConversationID returns an ID that is not used by any conversation
+func nextConversationID(conversations Conversations) int {
+	maxID := 0
 	for _, conv := range conversations.List {
-		fmt.Printf("🆔 %d: %s\n", conv.ID, conv.Query)
+		if conv.ID > maxID {
+			maxID = conv.ID
+		}
 	}
+	return maxID + 1
 }
 
 // loadConversationByID retrieves a specific conversation
@@ -208,48 +569,122 @@ func loadConversationByID(cid string) ([]map[string]string, int) {
 	return nil, 0
 }
 
-// saveConversation saves a conversation and returns its ID
-func saveConversation(history []map[string]string, existingCID int, query string) int {
+// saveConversation saves a conversation and returns its ID. New
+// conversations are associated with the current project and any tags and
+// attachment names are added to the conversation.
+func saveConversation(history []map[string]string, existingCID int, query, model string, tags, attachments []string) int {
 	conversations := loadAllConversations()
 	conversationID := existingCID
+	now := time.Now()
 
 	if existingCID == 0 {
-		conversationID = len(conversations.List) + 1
-		conversations.List = append(conversations.List, Conversation{ID: conversationID, History: history, Query: query})
+		conversationID = nextConversationID(conversations)
+		conversations.List = append(conversations.List, Conversation{
+			ID:          conversationID,
+			History:     history,
+			Query:       query,
+			Model:       model,
+			Tags:        mergeTags(nil, tags),
+			Project:     currentProject(),
+			Attachments: uniqueStrings(attachments),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
 	} else {
 		for i, conv := range conversations.List {
 			if conv.ID == existingCID {
 				conversations.List[i].History = history
+				conversations.List[i].Model = model
+				conversations.List[i].Tags = mergeTags(conv.Tags, tags)
+				conversations.List[i].Attachments = uniqueStrings(append(conv.Attachments, attachments...))
+				conversations.List[i].UpdatedAt = now
 			}
 		}
 	}
 
+	conversations.List = applyRetention(conversations.List, conversationID)
+	if err := writeConversations(conversations); err != nil {
+		fmt.Println("Error saving conversation:", err)
+	}
+	return conversationID
+}
+
+// writeConversations stores all conversations in the session file,
+// encrypting them when history.encryption.mode is set
+func writeConversations(conversations Conversations) error {
 	jsonData, err := json.MarshalIndent(conversations, "", "  ")
 	if err != nil {
-		fmt.Println("Error encoding conversation:", err)
-		return conversationID
+		return err
 	}
 
-	os.WriteFile(sessionFile, jsonData, 0644)
-	return conversationID
+	if historyEncryptionMode() != "" {
+		jsonData, err = encryptHistory(jsonData)
+		if err != nil {
+			return err
+		}
+	} else if sessionFileEncrypted() {
+		return fmt.Errorf("%s is encrypted, set history.encryption.mode or run `devopscli history decrypt`", sessionFile)
+	}
+
+	return writeSessionFile(jsonData)
+}
+
+// writeSessionFile atomically replaces the session file, readable only by
+// the current user
+func writeSessionFile(data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(sessionFile), ".devopscli_sessions-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	if err := tmpFile.Chmod(0600); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), sessionFile)
 }
 
-// loadAllConversations retrieves all stored conversations
+// loadAllConversations retrieves all stored conversations. An encrypted
+// store that cannot be decrypted is a fatal error, so it is never
+// overwritten with an empty list.
 func loadAllConversations() Conversations {
 	data, err := os.ReadFile(sessionFile)
 	if err != nil {
 		return Conversations{}
 	}
 
+	if encryption.IsEncrypted(data) {
+		data, err = decryptHistory(data)
+		if err != nil {
+			fmt.Printf("Error decrypting %s: %v\n", sessionFile, err)
+			os.Exit(1)
+		}
+	}
+
 	var conversations Conversations
 	json.Unmarshal(data, &conversations)
 	return conversations
 }
 
 func sendQueryToOpenWebUI(apiHost, apiKey, model string, history []map[string]string) (string, error) {
+	return sendRedactedQuery(apiHost, apiKey, model, newRedactor(), history)
+}
+
+// sendRedactedQuery sends the conversation with secrets redacted by
+// redactor. Requests that share a redactor use the same placeholder for the
+// same secret, so answers for parts of one input can be combined.
+func sendRedactedQuery(apiHost, apiKey, model string, redactor *redact.Redactor, history []map[string]string) (string, error) {
 	payload := map[string]interface{}{
 		"model":    model,
-		"messages": history,
+		"messages": redactMessages(redactor, history),
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -293,6 +728,125 @@ func sendQueryToOpenWebUI(apiHost, apiKey, model string, history []map[string]st
 	if len(jsonResponse.Choices) == 0 {
 		return "⚠️ No response received from OpenWebUI", nil
 	}
-	return jsonResponse.Choices[0].Message.Content, nil
+	return restoreResponse(redactor, jsonResponse.Choices[0].Message.Content), nil
 }
 
+// streamQueryToOpenWebUI sends the conversation with streaming enabled and
+// calls onChunk for every piece of the reply as it arrives. The full reply
+// is returned once the stream ends.
+func streamQueryToOpenWebUI(apiHost, apiKey, model string, history []map[string]string, onChunk func(string)) (string, error) {
+	redactor := newRedactor()
+	payload := map[string]interface{}{
+		"model":    model,
+		"messages": redactMessages(redactor, history),
+		"stream":   true,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/api/chat/completions", apiHost)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	// Servers that ignore "stream" reply with a regular JSON body
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		var jsonResponse struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal(body, &jsonResponse); err != nil {
+			return "", err
+		}
+		if len(jsonResponse.Choices) == 0 {
+			return "", fmt.Errorf("no response received from OpenWebUI")
+		}
+		content := restoreResponse(redactor, jsonResponse.Choices[0].Message.Content)
+		onChunk(content)
+		return content, nil
+	}
+
+	emit, flush := restoreStream(redactor, onChunk)
+	defer flush()
+
+	var full strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk struct {
+			Choices []struct {
+				Delta struct {
+					Content string `json:"content"`
+				} `json:"delta"`
+			} `json:"choices"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			continue
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		full.WriteString(chunk.Choices[0].Delta.Content)
+		emit(chunk.Choices[0].Delta.Content)
+	}
+	if err := scanner.Err(); err != nil {
+		return restoreResponse(redactor, full.String()), err
+	}
+	return restoreResponse(redactor, full.String()), nil
+}
+
+// openWebUISettings returns the OpenWebUI host, API key and model from
+// config.yaml, falling back to environment variables
+func openWebUISettings() (string, string, string, error) {
+	apiHost := viper.GetString("openwebui.host")
+	apiKey := viper.GetString("openwebui.api_key")
+	aiModel := viper.GetString("openwebui.model")
+
+	if apiHost == "" {
+		apiHost = os.Getenv("OPENWEB_API_HOST")
+	}
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENWEB_API_KEY")
+	}
+
+	if apiHost == "" || apiKey == "" {
+		return "", "", "", fmt.Errorf("OpenWebUI host and API key must be set in config.yaml or environment variables")
+	}
+	return apiHost, apiKey, aiModel, nil
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// useTempSessionFile points the conversation store at a temporary file
+func useTempSessionFile(t *testing.T) {
+	t.Helper()
+	original := sessionFile
+	sessionFile = filepath.Join(t.TempDir(), "sessions.json")
+	t.Cleanup(func() { sessionFile = original })
+}
+
+// newFakeModel starts a chat completions server that answers every request
+// with reply, called with the content of the last message
+func newFakeModel(t *testing.T, reply func(prompt string) string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Messages []map[string]string `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		prompt := ""
+		if len(body.Messages) > 0 {
+			prompt = body.Messages[len(body.Messages)-1]["content"]
+		}
+		fmt.Fprintf(w, `{"choices":[{"message":{"content":%q}}]}`, reply(prompt))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func threeTurnHistory() []map[string]string {
+	return []map[string]string{
+		{"role": "user", "content": "one"},
+		{"role": "assistant", "content": "reply one"},
+		{"role": "user", "content": "two"},
+		{"role": "assistant", "content": "reply two"},
+		{"role": "user", "content": "three"},
+		{"role": "assistant", "content": "reply three"},
+	}
+}
+
+// TestHistoryUpToTurn checks that a history is cut after the given turn
+func TestHistoryUpToTurn(t *testing.T) {
+	history, err := historyUpToTurn(threeTurnHistory(), 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 4 || history[3]["content"] != "reply two" {
+		t.Errorf("expected history to end at turn 2, got %v", history)
+	}
+
+	if _, err := historyUpToTurn(threeTurnHistory(), 4); err == nil {
+		t.Errorf("expected error for turn out of range")
+	}
+}
+
+// TestForkConversation checks that a fork references its parent and
+// leaves the original conversation untouched
+func TestForkConversation(t *testing.T) {
+	useTempSessionFile(t)
+
+	parentID := saveConversation(threeTurnHistory(), 0, "one", "gemma:2b", nil, nil)
+	fork, err := forkConversation(parentID, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if fork.ID == parentID || fork.ParentID != parentID || fork.ForkedAt != 1 {
+		t.Errorf("unexpected fork metadata: %+v", fork)
+	}
+	if len(fork.History) != 2 {
+		t.Errorf("expected 2 messages in fork, got %d", len(fork.History))
+	}
+
+	parent, _ := loadConversationByID("1")
+	if len(parent) != 6 {
+		t.Errorf("expected parent history to be unchanged, got %d messages", len(parent))
+	}
+}
+
+// TestHistoryForRetry checks that replies after the last user message are dropped
+func TestHistoryForRetry(t *testing.T) {
+	history, err := historyForRetry(threeTurnHistory())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(history) != 5 || history[4]["content"] != "three" {
+		t.Errorf("expected history to end with the last user message, got %v", history)
+	}
+}
+
+// TestEditorCommand checks $EDITOR arguments and the fallback for a blank
+// $EDITOR
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("EDITOR", "code --wait")
+	if got := editorCommand(); len(got) != 2 || got[0] != "code" || got[1] != "--wait" {
+		t.Errorf("unexpected editor command %q", got)
+	}
+	t.Setenv("EDITOR", "  ")
+	if got := editorCommand(); len(got) != 1 || got[0] != "vi" {
+		t.Errorf("expected vi for a blank $EDITOR, got %q", got)
+	}
+}
+
+// TestUndoLastExchange checks that the last exchange is removed from storage
+func TestUndoLastExchange(t *testing.T) {
+	useTempSessionFile(t)
+
+	id := saveConversation(threeTurnHistory(), 0, "one", "gemma:2b", nil, nil)
+	if err := undoLastExchange(id); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	history, _ := loadConversationByID("1")
+	if len(history) != 4 || history[3]["content"] != "reply two" {
+		t.Errorf("expected last exchange to be removed, got %v", history)
+	}
+
+	single := saveConversation(threeTurnHistory()[:2], 0, "one", "gemma:2b", nil, nil)
+	if err := undoLastExchange(single); err == nil {
+		t.Errorf("expected error when undoing the only exchange")
+	}
+}
+
+// TestSaveConversationProjectAndTags checks project scoping and tag merging
+func TestSaveConversationProjectAndTags(t *testing.T) {
+	useTempSessionFile(t)
+
+	id := saveConversation(threeTurnHistory(), 0, "one", "gemma:2b", []string{"incident", " "}, nil)
+	saveConversation(threeTurnHistory(), id, "one", "gemma:2b", []string{"INCIDENT", "ingress"}, nil)
+
+	conversations := loadAllConversations()
+	conv := conversations.List[0]
+	if conv.Project != currentProject() {
+		t.Errorf("expected project %q, got %q", currentProject(), conv.Project)
+	}
+	if len(conv.Tags) != 2 || conv.Tags[0] != "incident" || conv.Tags[1] != "ingress" {
+		t.Errorf("unexpected tags: %v", conv.Tags)
+	}
+
+	if got := conversationsForProject(conversations.List, "/somewhere/else"); len(got) != 0 {
+		t.Errorf("expected no conversations for another project, got %d", len(got))
+	}
+
+	conversations.List = append(conversations.List, Conversation{ID: 2, Query: "before projects"})
+	if got := conversationsForProject(conversations.List, "/somewhere/else"); len(got) != 1 || got[0].ID != 2 {
+		t.Errorf("expected the conversation without a project in every project, got %+v", got)
+	}
+}
+
+// TestEncryptedConversationStore checks that conversations are stored
+// encrypted with strict permissions and can be read back
+func TestEncryptedConversationStore(t *testing.T) {
+	useTempSessionFile(t)
+	viper.Set("history.encryption.mode", "passphrase")
+	viper.Set("history.encryption.key_env", "DEVOPSCLI_TEST_HISTORY_KEY")
+	t.Setenv("DEVOPSCLI_TEST_HISTORY_KEY", "correct horse")
+	t.Cleanup(func() {
+		viper.Set("history.encryption.mode", "")
+		cachedHistoryKey = ""
+	})
+
+	saveConversation(threeTurnHistory(), 0, "one", "gemma:2b", nil, nil)
+
+	data, err := os.ReadFile(sessionFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(data), "reply one") || !sessionFileEncrypted() {
+		t.Errorf("expected session file to be encrypted")
+	}
+
+	info, _ := os.Stat(sessionFile)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	history, _ := loadConversationByID("1")
+	if len(history) != 6 {
+		t.Errorf("expected decrypted history, got %d messages", len(history))
+	}
+
+	viper.Set("history.encryption.mode", "")
+	if err := writeConversations(loadAllConversations()); err == nil {
+		t.Errorf("expected error when writing plaintext over an encrypted store")
+	}
+}
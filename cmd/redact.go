@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/ruanbekker/devops-ai-cli/internal/redact"
+	"github.com/spf13/viper"
+)
+
+// newRedactor returns a redactor configured from redaction in the config,
+// or nil when redaction is disabled
+func newRedactor() *redact.Redactor {
+	if !viper.GetBool("redaction.enabled") {
+		return nil
+	}
+
+	rules := append([]redact.Rule{}, redact.DefaultRules...)
+	custom, err := redact.CustomRules(viper.GetStringSlice("redaction.patterns"))
+	if err != nil {
+		fmt.Println("⚠️ Ignoring redaction.patterns:", err)
+	} else {
+		rules = append(rules, custom...)
+	}
+	return redact.New(rules, viper.GetBool("redaction.entropy"))
+}
+
+// redactMessages returns a copy of the messages with secrets replaced by
+// placeholders and logs what was redacted
+func redactMessages(r *redact.Redactor, messages []map[string]string) []map[string]string {
+	if r == nil {
+		return messages
+	}
+
+	seen := len(r.Findings())
+	redacted := make([]map[string]string, 0, len(messages))
+	for _, msg := range messages {
+		copied := map[string]string{}
+		for key, value := range msg {
+			copied[key] = value
+		}
+		copied["content"] = r.Redact(msg["content"])
+		redacted = append(redacted, copied)
+	}
+
+	for _, finding := range r.Findings()[seen:] {
+		logger.Log(fmt.Sprintf("redact: replaced %s with %s", finding.Kind, finding.Placeholder))
+	}
+	return redacted
+}
+
+// restoreResponse puts the redacted secrets back into a response when
+// redaction.restore is enabled
+func restoreResponse(r *redact.Redactor, response string) string {
+	if r == nil || !viper.GetBool("redaction.restore") {
+		return response
+	}
+	return r.Restore(response)
+}
+
+// restoreStream wraps onChunk so streamed placeholders are restored when
+// redaction.restore is enabled. The returned flush must be called once the
+// stream ends.
+func restoreStream(r *redact.Redactor, onChunk func(string)) (func(string), func()) {
+	if r == nil || !viper.GetBool("redaction.restore") {
+		return onChunk, func() {}
+	}
+	stream := r.NewStream(onChunk)
+	return stream.Write, stream.Flush
+}
+
+// restoreHistory puts secrets from the local history back into a history
+// that was pushed with secrets redacted
+func restoreHistory(local, remote []map[string]string) []map[string]string {
+	redactor := newRedactor()
+	if redactor == nil {
+		return remote
+	}
+	for _, msg := range local {
+		redactor.Redact(msg["content"])
+	}
+	for _, msg := range remote {
+		msg["content"] = redactor.Restore(msg["content"])
+	}
+	return remote
+}
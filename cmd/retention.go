@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Flags
+var pruneDryRun bool
+
+// retentionPolicy limits how many conversations are kept. Zero values
+// disable a limit.
+type retentionPolicy struct {
+	MaxAge           time.Duration
+	MaxConversations int
+	MaxSize          int64
+}
+
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove conversations outside the retention policy",
+	Long: `Removes conversations that exceed the limits in history.retention
+(max_age, max_conversations, max_size). Oldest conversations are removed first
+and pinned conversations are never removed.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		policy, err := loadRetentionPolicy()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		if policy.disabled() {
+			fmt.Println("No retention policy configured in history.retention.")
+			return
+		}
+
+		conversations := loadAllConversations()
+		kept, pruned := pruneConversations(conversations.List, policy, time.Now(), 0)
+		if len(pruned) == 0 {
+			fmt.Println("No conversations to prune.")
+			return
+		}
+
+		for _, conv := range pruned {
+			updated := "unknown"
+			if t := lastUpdated(conv); !t.IsZero() {
+				updated = t.Format("2006-01-02")
+			}
+			fmt.Printf("🗑️  %d: %s (last updated %s)\n", conv.ID, conv.Query, updated)
+		}
+
+		if pruneDryRun {
+			fmt.Printf("\n%d conversation(s) would be pruned.\n", len(pruned))
+			return
+		}
+
+		conversations.List = kept
+		if err := writeConversations(conversations); err != nil {
+			fmt.Println("Error pruning conversations:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n✅ Pruned %d conversation(s).\n", len(pruned))
+	},
+}
+
+var historyPinCmd = &cobra.Command{
+	Use:   "pin <id>",
+	Short: "Pin a conversation so it is never pruned",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setPinned(args[0], true)
+	},
+}
+
+var historyUnpinCmd = &cobra.Command{
+	Use:   "unpin <id>",
+	Short: "Unpin a conversation",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		setPinned(args[0], false)
+	},
+}
+
+func init() {
+	historyPruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be pruned without removing anything")
+	historyCmd.AddCommand(historyPruneCmd)
+	historyCmd.AddCommand(historyPinCmd)
+	historyCmd.AddCommand(historyUnpinCmd)
+}
+
+// setPinned pins or unpins a stored conversation
+func setPinned(cid string, pinned bool) {
+	id, err := strconv.Atoi(cid)
+	if err != nil {
+		fmt.Printf("Error: invalid conversation ID %q\n", cid)
+		os.Exit(1)
+	}
+
+	conversations := loadAllConversations()
+	for i, conv := range conversations.List {
+		if conv.ID != id {
+			continue
+		}
+		conversations.List[i].Pinned = pinned
+		if err := writeConversations(conversations); err != nil {
+			fmt.Println("Error saving conversations:", err)
+			os.Exit(1)
+		}
+		if pinned {
+			fmt.Printf("📌 Conversation ID %d is pinned.\n", id)
+		} else {
+			fmt.Printf("✅ Conversation ID %d is unpinned.\n", id)
+		}
+		return
+	}
+
+	fmt.Printf("Error: conversation ID %d not found\n", id)
+	os.Exit(1)
+}
+
+// loadRetentionPolicy reads history.retention from the config
+func loadRetentionPolicy() (retentionPolicy, error) {
+	policy := retentionPolicy{
+		MaxConversations: viper.GetInt("history.retention.max_conversations"),
+	}
+
+	if value := viper.GetString("history.retention.max_age"); value != "" {
+		age, err := parseAge(value)
+		if err != nil {
+			return policy, fmt.Errorf("invalid history.retention.max_age %q: %w", value, err)
+		}
+		policy.MaxAge = age
+	}
+
+	if value := viper.GetString("history.retention.max_size"); value != "" {
+		size, err := parseByteSize(value)
+		if err != nil {
+			return policy, fmt.Errorf("invalid history.retention.max_size %q: %w", value, err)
+		}
+		policy.MaxSize = size
+	}
+	return policy, nil
+}
+
+// disabled reports whether no limit is configured
+func (p retentionPolicy) disabled() bool {
+	return p.MaxAge <= 0 && p.MaxConversations <= 0 && p.MaxSize <= 0
+}
+
+// parseByteSize parses sizes such as 512KB, 10MB or 1GB (powers of 1024)
+func parseByteSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(value, unit.suffix) {
+			multiplier = unit.size
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * multiplier, nil
+}
+
+// conversationSize returns the approximate stored size of a conversation
+func conversationSize(conv Conversation) int64 {
+	data, err := json.Marshal(conv)
+	if err != nil {
+		return 0
+	}
+	return int64(len(data))
+}
+
+// lastUpdated returns when a conversation was last updated, falling back to
+// when it was created for conversations saved without an update time
+func lastUpdated(conv Conversation) time.Time {
+	if conv.UpdatedAt.IsZero() {
+		return conv.CreatedAt
+	}
+	return conv.UpdatedAt
+}
+
+// pruneConversations applies a retention policy, removing the least
+// recently updated conversations first. Pinned conversations and the
+// conversation with keepID are never removed, and conversations without
+// any timestamp are never removed for their age.
+func pruneConversations(list []Conversation, policy retentionPolicy, now time.Time, keepID int) ([]Conversation, []Conversation) {
+	if policy.disabled() {
+		return list, nil
+	}
+
+	// Order candidates from oldest to newest
+	order := make([]int, len(list))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return lastUpdated(list[order[a]]).Before(lastUpdated(list[order[b]]))
+	})
+
+	remove := map[int]bool{}
+	exempt := func(conv Conversation) bool {
+		return conv.Pinned || (keepID != 0 && conv.ID == keepID)
+	}
+
+	if policy.MaxAge > 0 {
+		cutoff := now.Add(-policy.MaxAge)
+		for _, i := range order {
+			updated := lastUpdated(list[i])
+			if !exempt(list[i]) && !updated.IsZero() && updated.Before(cutoff) {
+				remove[i] = true
+			}
+		}
+	}
+
+	if policy.MaxConversations > 0 {
+		count := len(list) - len(remove)
+		for _, i := range order {
+			if count <= policy.MaxConversations {
+				break
+			}
+			if !remove[i] && !exempt(list[i]) {
+				remove[i] = true
+				count--
+			}
+		}
+	}
+
+	if policy.MaxSize > 0 {
+		total := int64(0)
+		for i, conv := range list {
+			if !remove[i] {
+				total += conversationSize(conv)
+			}
+		}
+		for _, i := range order {
+			if total <= policy.MaxSize {
+				break
+			}
+			if !remove[i] && !exempt(list[i]) {
+				remove[i] = true
+				total -= conversationSize(list[i])
+			}
+		}
+	}
+
+	kept := []Conversation{}
+	pruned := []Conversation{}
+	for i, conv := range list {
+		if remove[i] {
+			pruned = append(pruned, conv)
+		} else {
+			kept = append(kept, conv)
+		}
+	}
+	return kept, pruned
+}
+
+// applyRetention prunes conversations at save time, keeping the
+// conversation that is being saved
+func applyRetention(list []Conversation, keepID int) []Conversation {
+	policy, err := loadRetentionPolicy()
+	if err != nil {
+		fmt.Println("⚠️ Skipping retention:", err)
+		return list
+	}
+
+	kept, pruned := pruneConversations(list, policy, time.Now(), keepID)
+	if len(pruned) > 0 {
+		logger.Log(fmt.Sprintf("retention: pruned %d conversation(s)", len(pruned)))
+	}
+	return kept
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+)
+
+func retentionConversations(now time.Time) []Conversation {
+	return []Conversation{
+		{ID: 1, Query: "old", UpdatedAt: now.AddDate(0, 0, -100)},
+		{ID: 2, Query: "old pinned", Pinned: true, UpdatedAt: now.AddDate(0, 0, -200)},
+		{ID: 3, Query: "recent", UpdatedAt: now.AddDate(0, 0, -10)},
+		{ID: 4, Query: "newest", UpdatedAt: now},
+	}
+}
+
+func prunedIDs(pruned []Conversation) []int {
+	ids := []int{}
+	for _, conv := range pruned {
+		ids = append(ids, conv.ID)
+	}
+	return ids
+}
+
+// TestPruneConversationsMaxAge ensures old conversations are pruned unless pinned
+func TestPruneConversationsMaxAge(t *testing.T) {
+	now := time.Now()
+	kept, pruned := pruneConversations(retentionConversations(now), retentionPolicy{MaxAge: 90 * 24 * time.Hour}, now, 0)
+	if ids := prunedIDs(pruned); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("expected conversation 1 to be pruned, got %v", ids)
+	}
+	if len(kept) != 3 {
+		t.Errorf("expected 3 conversations kept, got %d", len(kept))
+	}
+}
+
+// TestPruneConversationsMaxAgeNoUpdateTime checks the creation time is used
+// when there is no update time, and undated conversations are kept
+func TestPruneConversationsMaxAgeNoUpdateTime(t *testing.T) {
+	now := time.Now()
+	list := []Conversation{
+		{ID: 1, Query: "old", CreatedAt: now.AddDate(0, 0, -100)},
+		{ID: 2, Query: "recent", CreatedAt: now.AddDate(0, 0, -10)},
+		{ID: 3, Query: "undated"},
+	}
+	_, pruned := pruneConversations(list, retentionPolicy{MaxAge: 90 * 24 * time.Hour}, now, 0)
+	if ids := prunedIDs(pruned); len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("expected conversation 1 to be pruned, got %v", ids)
+	}
+}
+
+// TestPruneConversationsMaxConversations ensures the oldest are pruned first
+func TestPruneConversationsMaxConversations(t *testing.T) {
+	now := time.Now()
+	_, pruned := pruneConversations(retentionConversations(now), retentionPolicy{MaxConversations: 2}, now, 0)
+	if ids := prunedIDs(pruned); len(ids) != 2 || ids[0] != 1 || ids[1] != 3 {
+		t.Errorf("expected conversations 1 and 3 to be pruned, got %v", ids)
+	}
+
+	_, pruned = pruneConversations(retentionConversations(now), retentionPolicy{MaxConversations: 1}, now, 3)
+	for _, id := range prunedIDs(pruned) {
+		if id == 3 {
+			t.Errorf("expected kept conversation 3 not to be pruned")
+		}
+	}
+}
+
+// TestParseByteSize checks size units
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]int64{"512": 512, "1KB": 1024, "10MB": 10 << 20, "1gb": 1 << 30}
+	for value, expected := range cases {
+		got, err := parseByteSize(value)
+		if err != nil || got != expected {
+			t.Errorf("parseByteSize(%q) = %d, %v; expected %d", value, got, err, expected)
+		}
+	}
+	if _, err := parseByteSize("lots"); err == nil {
+		t.Errorf("expected error for invalid size")
+	}
+}
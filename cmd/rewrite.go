@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+	"github.com/ruanbekker/devops-ai-cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var rewriteFilePath string
+var rewriteYes bool
+var rewriteDryRun bool
+var rewritePatchPath string
+
+const multiStagePrompt = `Rewrite this Dockerfile as a multi-stage build. Build the application and
+install build dependencies in a builder stage, then copy only what is needed
+at runtime into a small, pinned final stage that runs as a non-root user and
+has a HEALTHCHECK. Keep the ports, environment, working directory and the
+command the application starts with.%s
+Reply with the complete Dockerfile in a single fenced code block and nothing
+else.
+
+%s`
+
+var rewriteCmd = &cobra.Command{
+	Use:   "rewrite -f <Dockerfile> [instruction]",
+	Short: "Rewrite a Dockerfile as a multi-stage build",
+	Long: `Asks the model to rewrite a Dockerfile as a multi-stage build with a small
+runtime stage, fixing the lint findings on the way. The rewrite is checked with
+the Dockerfile parser and must have more than one stage; the model is asked
+again when it does not. The changes are shown as a diff against the original
+and written after you confirm (or straight away with --yes), keeping a .bak
+backup. An optional instruction is passed on, e.g. "use distroless".`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if rewriteFilePath == "" || rewriteFilePath == "-" {
+			fmt.Println("Error: Please specify a file with -f")
+			os.Exit(1)
+		}
+
+		content, err := readOptimizeInput(rewriteFilePath)
+		if err != nil {
+			fmt.Printf("Error reading file: %v\n", err)
+			os.Exit(1)
+		}
+		if kind := filetype.Detect(rewriteFilePath, content); kind != filetype.Dockerfile {
+			fmt.Printf("Error: rewrite supports Dockerfiles, %s looks like a %s\n", rewriteFilePath, kind)
+			os.Exit(1)
+		}
+		if err := validate.Dockerfile(content); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		apiHost, apiKey, aiModel, err := openWebUISettings()
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		instruction := ""
+		if len(args) > 0 {
+			instruction = args[0]
+		}
+		logger.Log(fmt.Sprintf("rewrite: asking %s model for a multi-stage build of %s", aiModel, rewriteFilePath))
+
+		revised, err := rewriteDockerfile(apiHost, apiKey, aiModel, content, instruction)
+		if err != nil {
+			fmt.Printf("Error from AI: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := reviewChanges(rewriteFilePath, content, revised, rewritePatchPath, !rewriteDryRun, rewriteYes, os.Stdin, os.Stdout); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		// The model may leave some findings, such as which base image tag to use
+		if remaining, err := rules.CheckDockerfile(revised, rules.DockerfileRules); err == nil && len(remaining) > 0 {
+			fmt.Println("\n⚠️ Still needs a manual fix:")
+			for _, f := range remaining {
+				fmt.Printf("   line %d [%s] %s %s: %s\n", f.Line, f.Severity, f.Rule, f.Resource, f.Message)
+			}
+		}
+	},
+}
+
+func init() {
+	rewriteCmd.Flags().StringVarP(&rewriteFilePath, "file", "f", "", "Dockerfile to rewrite")
+	rewriteCmd.Flags().BoolVarP(&rewriteYes, "yes", "y", false, "Write the rewrite without asking for confirmation")
+	rewriteCmd.Flags().BoolVar(&rewriteDryRun, "dry-run", false, "Only show the diff")
+	rewriteCmd.Flags().StringVar(&rewritePatchPath, "output-patch", "", "Write the changes as a unified diff to this file")
+	rootCmd.AddCommand(rewriteCmd)
+}
+
+// rewriteDockerfile asks the model for a multi-stage version of a
+// Dockerfile, passing on the lint findings to fix
+func rewriteDockerfile(apiHost, apiKey, model, content, instruction string) (string, error) {
+	findings := ""
+	if known := localFindings(filetype.Dockerfile, content, rules.DockerfileRules); len(known) > 0 {
+		findings = "\nAlso fix these problems a linter found:\n" + strings.TrimRight(findingsList(known), "\n")
+	}
+	prompt := fmt.Sprintf(multiStagePrompt, findings, content)
+	if instruction != "" {
+		prompt = instruction + "\n\n" + prompt
+	}
+
+	return requestRevision(apiHost, apiKey, model, prompt, content, checkMultiStage)
+}
+
+// checkMultiStage checks a rewritten Dockerfile parses and has more than
+// one stage
+func checkMultiStage(content string) error {
+	if err := validate.Dockerfile(content); err != nil {
+		return err
+	}
+	stages, err := rules.Stages(content)
+	if err != nil {
+		return err
+	}
+	if stages < 2 {
+		return fmt.Errorf("the Dockerfile has a single stage, split the build and the runtime into separate stages")
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRewriteDockerfile checks a single stage rewrite is sent back and the
+// lint findings are part of the prompt
+func TestRewriteDockerfile(t *testing.T) {
+	multiStage := "FROM golang:1.22 AS build\nCOPY . .\nRUN go build -o /app\n\nFROM gcr.io/distroless/static:nonroot\nCOPY --from=build /app /app\nENTRYPOINT [\"/app\"]"
+	replies := []string{"```dockerfile\nFROM golang:1.22\nCOPY . .\n```", "```dockerfile\n" + multiStage + "\n```"}
+	prompts := []string{}
+	server := newFakeModel(t, func(prompt string) string {
+		prompts = append(prompts, prompt)
+		return replies[len(prompts)-1]
+	})
+
+	revised, err := rewriteDockerfile(server.URL, "key", "model", "FROM golang\nCOPY . .\nRUN go build -o /app\n", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revised != multiStage+"\n" {
+		t.Errorf("unexpected rewrite %q", revised)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(prompts))
+	}
+	if !strings.Contains(prompts[0], "`base-image-tag`") {
+		t.Errorf("expected the findings in the prompt, got %q", prompts[0])
+	}
+	if !strings.Contains(prompts[1], "single stage") {
+		t.Errorf("expected the single stage error in the second prompt, got %q", prompts[1])
+	}
+}
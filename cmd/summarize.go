@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// Flags
+var summarizeNote string
+var summarizeModel string
+var summarizeRegenerate bool
+
+const summaryPrompt = `Summarise the following DevOps conversation as a runbook note.
+Reply in Markdown using exactly these sections:
+
+## Problem
+## Root Cause
+## Commands Run
+## Resolution
+
+List commands in fenced code blocks. Write "Unknown" for a section the conversation does not cover.
+
+Conversation:
+
+%s`
+
+var historySummarizeCmd = &cobra.Command{
+	Use:   "summarize <id>",
+	Short: "Summarise a conversation into a reusable note",
+	Long: `Asks the model for a structured summary (problem, root cause, commands run,
+resolution) of a stored conversation and stores it with the conversation.
+Use --note to also save it as a Markdown file, e.g. in a runbook repository.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			fmt.Printf("Error: invalid conversation ID %q\n", args[0])
+			os.Exit(1)
+		}
+
+		conv, found := findConversation(id)
+		if !found {
+			fmt.Printf("Error: conversation ID %d not found\n", id)
+			os.Exit(1)
+		}
+
+		summary := conv.Summary
+		if summary == "" || summarizeRegenerate {
+			apiHost, apiKey, aiModel, err := openWebUISettings()
+			if err != nil {
+				fmt.Println("Error:", err)
+				os.Exit(1)
+			}
+			if summarizeModel != "" {
+				aiModel = summarizeModel
+			}
+
+			logger.Log(fmt.Sprintf("summarize: using %s model for conversation ID %d", aiModel, id))
+			summary, err = summarizeConversation(apiHost, apiKey, aiModel, conv)
+			if err != nil {
+				fmt.Printf("Error from OpenWebUI: %v\n", err)
+				os.Exit(1)
+			}
+			conv.Summary = summary
+		}
+
+		renderer, err := glamour.NewTermRenderer(
+			glamour.WithAutoStyle(),
+			glamour.WithWordWrap(80),
+		)
+		if err != nil {
+			fmt.Printf("Error initializing renderer: %v\n", err)
+			os.Exit(1)
+		}
+
+		renderedOutput, err := renderer.Render(summary)
+		if err != nil {
+			fmt.Printf("Error rendering markdown: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Println(renderedOutput)
+
+		if summarizeNote != "" {
+			path, err := writeSummaryNote(summarizeNote, conv)
+			if err != nil {
+				fmt.Printf("Error writing note: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Printf("📄 Note saved to %s\n", path)
+		}
+	},
+}
+
+func init() {
+	historySummarizeCmd.Flags().StringVarP(&summarizeNote, "note", "o", "", "Also save the summary as a Markdown note (file or directory)")
+	historySummarizeCmd.Flags().StringVarP(&summarizeModel, "model", "m", "", "Model to use instead of openwebui.model")
+	historySummarizeCmd.Flags().BoolVar(&summarizeRegenerate, "regenerate", false, "Ask the model again even if a summary is stored")
+	historyCmd.AddCommand(historySummarizeCmd)
+}
+
+// findConversation returns a stored conversation by ID
+func findConversation(id int) (Conversation, bool) {
+	for _, conv := range loadAllConversations().List {
+		if conv.ID == id {
+			return conv, true
+		}
+	}
+	return Conversation{}, false
+}
+
+// summarizeConversation asks the model for a summary and stores it with
+// the conversation
+func summarizeConversation(apiHost, apiKey, model string, conv Conversation) (string, error) {
+	var transcript strings.Builder
+	for _, msg := range conv.History {
+		fmt.Fprintf(&transcript, "%s: %s\n\n", strings.ToUpper(msg["role"]), strings.TrimSpace(msg["content"]))
+	}
+
+	summary, err := sendQueryToOpenWebUI(apiHost, apiKey, model, []map[string]string{
+		{"role": "user", "content": fmt.Sprintf(summaryPrompt, transcript.String())},
+	})
+	if err != nil {
+		return "", err
+	}
+	summary = strings.TrimSpace(summary)
+
+	conversations := loadAllConversations()
+	for i := range conversations.List {
+		if conversations.List[i].ID == conv.ID {
+			conversations.List[i].Summary = summary
+		}
+	}
+	if err := writeConversations(conversations); err != nil {
+		return summary, err
+	}
+	return summary, nil
+}
+
+// writeSummaryNote writes the summary as a Markdown note. When path is a
+// directory the file name is derived from the conversation title.
+func writeSummaryNote(path string, conv Conversation) (string, error) {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, slugify(conv.Query)+".md")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", strings.Join(strings.Fields(conv.Query), " "))
+	details := []string{fmt.Sprintf("Conversation ID: %d", conv.ID)}
+	if conv.Model != "" {
+		details = append(details, "Model: "+conv.Model)
+	}
+	if !conv.UpdatedAt.IsZero() {
+		details = append(details, "Date: "+conv.UpdatedAt.Format("2006-01-02"))
+	}
+	if len(conv.Tags) > 0 {
+		details = append(details, "Tags: "+strings.Join(conv.Tags, ", "))
+	}
+	fmt.Fprintf(&b, "_%s_\n\n%s\n", strings.Join(details, " • "), conv.Summary)
+
+	return path, os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// slugify turns a title into a file name friendly slug
+func slugify(title string) string {
+	words := tokenize(title)
+	if len(words) > 8 {
+		words = words[:8]
+	}
+	if len(words) == 0 {
+		return "conversation"
+	}
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestSummarizeConversation checks that the summary is stored and written as a note
+func TestSummarizeConversation(t *testing.T) {
+	useTempSessionFile(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"choices":[{"message":{"content":"## Problem\nIngress 502"}}]}`)
+	}))
+	defer server.Close()
+
+	id := saveConversation(threeTurnHistory(), 0, "Ingress returns 502", "gemma:2b", nil, nil)
+	conv, _ := findConversation(id)
+
+	summary, err := summarizeConversation(server.URL, "key", "gemma:2b", conv)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stored, _ := findConversation(id)
+	if stored.Summary != summary || !strings.Contains(summary, "Ingress 502") {
+		t.Errorf("expected summary to be stored, got %q", stored.Summary)
+	}
+
+	dir := t.TempDir()
+	path, err := writeSummaryNote(dir, stored)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if path != filepath.Join(dir, "ingress-returns-502.md") {
+		t.Errorf("unexpected note path %s", path)
+	}
+	note, _ := os.ReadFile(path)
+	if !strings.Contains(string(note), "# Ingress returns 502") || !strings.Contains(string(note), "## Problem") {
+		t.Errorf("unexpected note content: %s", note)
+	}
+}
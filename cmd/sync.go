@@ -0,0 +1,246 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/logger"
+	"github.com/ruanbekker/devops-ai-cli/internal/openwebui"
+	"github.com/spf13/cobra"
+)
+
+// Flags
+var syncDryRun bool
+var syncPullOnly bool
+var syncPushOnly bool
+
+// syncOptions controls the direction of a sync
+type syncOptions struct {
+	DryRun bool
+	Pull   bool
+	Push   bool
+}
+
+// syncReport lists what a sync changed
+type syncReport struct {
+	Pushed   []string
+	Pulled   []string
+	Created  []string
+	Missing  []string
+	UpToDate int
+}
+
+var historySyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Sync conversations with the OpenWebUI chats API",
+	Long: `Two-way sync between the local conversation store and the chats of the
+OpenWebUI user owning the API key. Conversations started in the CLI appear in
+the web interface and chats started in the web interface can be continued with
+query --cid. When both sides changed, the most recently updated one wins.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if syncPullOnly && syncPushOnly {
+			fmt.Println("Error: --pull-only and --push-only cannot be combined.")
+			os.Exit(1)
+		}
+
+		apiHost, apiKey, _, err := openWebUISettings()
+		if err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+
+		opts := syncOptions{DryRun: syncDryRun, Pull: !syncPushOnly, Push: !syncPullOnly}
+		conversations := loadAllConversations()
+		synced, report, err := syncConversations(conversations, openwebui.NewClient(apiHost, apiKey), opts)
+
+		for _, line := range report.Created {
+			fmt.Println("⬆️ ", line)
+		}
+		for _, line := range report.Pushed {
+			fmt.Println("⬆️ ", line)
+		}
+		for _, line := range report.Pulled {
+			fmt.Println("⬇️ ", line)
+		}
+		for _, line := range report.Missing {
+			fmt.Println("⚠️ ", line)
+		}
+
+		if err != nil {
+			// Save what was synced before the error, so chats created in
+			// this run keep their remote ID and are not created again
+			if !syncDryRun {
+				if err := writeConversations(synced); err != nil {
+					fmt.Println("Error saving conversations:", err)
+				}
+			}
+			fmt.Println("Error syncing with OpenWebUI:", err)
+			os.Exit(1)
+		}
+
+		if syncDryRun {
+			fmt.Printf("\nDry run: %d to create, %d to push, %d to pull, %d up to date.\n",
+				len(report.Created), len(report.Pushed), len(report.Pulled), report.UpToDate)
+			return
+		}
+
+		if err := writeConversations(synced); err != nil {
+			fmt.Println("Error saving conversations:", err)
+			os.Exit(1)
+		}
+		fmt.Printf("\n✅ Synced: %d created, %d pushed, %d pulled, %d up to date.\n",
+			len(report.Created), len(report.Pushed), len(report.Pulled), report.UpToDate)
+	},
+}
+
+func init() {
+	historySyncCmd.Flags().BoolVar(&syncDryRun, "dry-run", false, "Show what would be synced without changing anything")
+	historySyncCmd.Flags().BoolVar(&syncPullOnly, "pull-only", false, "Only download chats from OpenWebUI")
+	historySyncCmd.Flags().BoolVar(&syncPushOnly, "push-only", false, "Only upload conversations to OpenWebUI")
+	historyCmd.AddCommand(historySyncCmd)
+}
+
+// conversationToChat converts a stored conversation to an OpenWebUI chat
+// with secrets redacted
+func conversationToChat(conv Conversation) openwebui.Chat {
+	chat := openwebui.Chat{ID: conv.RemoteID, Title: conv.Query, Model: conv.Model, Tags: conv.Tags}
+	for _, msg := range redactMessages(newRedactor(), conv.History) {
+		chat.Messages = append(chat.Messages, openwebui.Message{Role: msg["role"], Content: msg["content"]})
+	}
+	return chat
+}
+
+// chatHistory converts OpenWebUI chat messages to a conversation history
+func chatHistory(chat openwebui.Chat) []map[string]string {
+	history := []map[string]string{}
+	for _, msg := range chat.Messages {
+		history = append(history, map[string]string{"role": msg.Role, "content": msg.Content})
+	}
+	return history
+}
+
+// syncConversations reconciles local conversations with OpenWebUI chats.
+// Conversations are matched on their remote ID and the side with the newer
+// update time wins. Local conversations without a remote ID are created
+// remotely and unknown remote chats are added locally. On an error the
+// conversations synced so far are returned along with it.
+func syncConversations(conversations Conversations, client *openwebui.Client, opts syncOptions) (Conversations, syncReport, error) {
+	report := syncReport{}
+
+	remoteChats, err := client.ListChats()
+	if err != nil {
+		return conversations, report, err
+	}
+	remoteByID := map[string]openwebui.ChatSummary{}
+	for _, chat := range remoteChats {
+		remoteByID[chat.ID] = chat
+	}
+
+	list := append([]Conversation{}, conversations.List...)
+	known := map[string]bool{}
+
+	for i := range list {
+		conv := &list[i]
+
+		// New local conversations are created in OpenWebUI
+		if conv.RemoteID == "" {
+			if !opts.Push {
+				continue
+			}
+			line := fmt.Sprintf("%d: %s", conv.ID, conv.Query)
+			if opts.DryRun {
+				report.Created = append(report.Created, line)
+				continue
+			}
+			created, err := client.CreateChat(conversationToChat(*conv))
+			if err != nil {
+				return Conversations{List: list}, report, err
+			}
+			report.Created = append(report.Created, line)
+			conv.RemoteID = created.ID
+			conv.UpdatedAt = created.UpdatedAt
+			known[created.ID] = true
+			continue
+		}
+
+		known[conv.RemoteID] = true
+		remote, ok := remoteByID[conv.RemoteID]
+		if !ok {
+			report.Missing = append(report.Missing, fmt.Sprintf("%d: %s (no longer in OpenWebUI, kept locally)", conv.ID, conv.Query))
+			continue
+		}
+
+		// OpenWebUI stores second precision timestamps
+		localTime, remoteTime := conv.UpdatedAt.Unix(), remote.UpdatedAt.Unix()
+		switch {
+		case remoteTime > localTime && opts.Pull:
+			line := fmt.Sprintf("%d: %s", conv.ID, conv.Query)
+			if opts.DryRun {
+				report.Pulled = append(report.Pulled, line)
+				continue
+			}
+			chat, err := client.GetChat(conv.RemoteID)
+			if err != nil {
+				return Conversations{List: list}, report, err
+			}
+			report.Pulled = append(report.Pulled, line)
+			conv.History = restoreHistory(conv.History, chatHistory(chat))
+			conv.UpdatedAt = chat.UpdatedAt
+			if chat.Title != "" {
+				conv.Query = chat.Title
+			}
+
+		case localTime > remoteTime && opts.Push:
+			line := fmt.Sprintf("%d: %s", conv.ID, conv.Query)
+			if opts.DryRun {
+				report.Pushed = append(report.Pushed, line)
+				continue
+			}
+			updated, err := client.UpdateChat(conv.RemoteID, conversationToChat(*conv))
+			if err != nil {
+				return Conversations{List: list}, report, err
+			}
+			report.Pushed = append(report.Pushed, line)
+			conv.UpdatedAt = updated.UpdatedAt
+
+		default:
+			report.UpToDate++
+		}
+	}
+
+	// Chats started in the web interface are added locally
+	if opts.Pull {
+		nextID := nextConversationID(conversations)
+		for _, remote := range remoteChats {
+			if known[remote.ID] {
+				continue
+			}
+			line := fmt.Sprintf("%d: %s (new from OpenWebUI)", nextID, remote.Title)
+			if opts.DryRun {
+				report.Pulled = append(report.Pulled, line)
+				nextID++
+				continue
+			}
+			chat, err := client.GetChat(remote.ID)
+			if err != nil {
+				return Conversations{List: list}, report, err
+			}
+			report.Pulled = append(report.Pulled, line)
+			list = append(list, Conversation{
+				ID:        nextID,
+				History:   chatHistory(chat),
+				Query:     chat.Title,
+				Model:     chat.Model,
+				Tags:      mergeTags(nil, chat.Tags),
+				RemoteID:  chat.ID,
+				CreatedAt: chat.UpdatedAt,
+				UpdatedAt: chat.UpdatedAt,
+			})
+			nextID++
+		}
+	}
+
+	logger.Log(fmt.Sprintf("sync: %d remote chat(s), %d local conversation(s)", len(remoteChats), len(list)))
+	return Conversations{List: list}, report, nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/openwebui"
+)
+
+// newFakeOpenWebUI returns a stand-in for the OpenWebUI chats API holding
+// chats as id -> {id, title, chat, updated_at}
+func newFakeOpenWebUI(t *testing.T, chats map[string]map[string]interface{}) *httptest.Server {
+	t.Helper()
+	next := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/chats/")
+		switch {
+		case id == "list":
+			list := []map[string]interface{}{}
+			if r.URL.Query().Get("page") == "1" {
+				for _, chat := range chats {
+					list = append(list, chat)
+				}
+			}
+			json.NewEncoder(w).Encode(list)
+		case r.Method == "GET":
+			json.NewEncoder(w).Encode(chats[id])
+		case r.Method == "POST":
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if id == "new" {
+				next++
+				id = fmt.Sprintf("new-%d", next)
+			}
+			chat := body["chat"].(map[string]interface{})
+			chats[id] = map[string]interface{}{"id": id, "title": chat["title"], "chat": chat, "updated_at": time.Now().Unix()}
+			json.NewEncoder(w).Encode(chats[id])
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func remoteChat(id, title string, updated time.Time, messages ...string) map[string]interface{} {
+	list := []map[string]interface{}{}
+	for i, content := range messages {
+		role := "user"
+		if i%2 == 1 {
+			role = "assistant"
+		}
+		list = append(list, map[string]interface{}{"id": fmt.Sprint(i), "role": role, "content": content})
+	}
+	return map[string]interface{}{
+		"id":         id,
+		"title":      title,
+		"updated_at": updated.Unix(),
+		"chat":       map[string]interface{}{"title": title, "messages": list},
+	}
+}
+
+// TestSyncConversations checks pushing, pulling and conflict resolution
+func TestSyncConversations(t *testing.T) {
+	now := time.Now()
+	chats := map[string]map[string]interface{}{
+		"web":   remoteChat("web", "started in the browser", now, "hi", "hello"),
+		"older": remoteChat("older", "changed locally", now.Add(-time.Hour), "a", "b"),
+		"newer": remoteChat("newer", "changed remotely", now, "c", "d", "e", "f"),
+	}
+	server := newFakeOpenWebUI(t, chats)
+
+	local := Conversations{List: []Conversation{
+		{ID: 1, Query: "cli only", History: threeTurnHistory(), UpdatedAt: now},
+		{ID: 2, Query: "changed locally", RemoteID: "older", History: threeTurnHistory(), UpdatedAt: now},
+		{ID: 3, Query: "changed remotely", RemoteID: "newer", History: threeTurnHistory()[:2], UpdatedAt: now.Add(-time.Hour)},
+	}}
+
+	client := openwebui.NewClient(server.URL, "key")
+	synced, report, err := syncConversations(local, client, syncOptions{Pull: true, Push: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(report.Created) != 1 || len(report.Pushed) != 1 || len(report.Pulled) != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(synced.List) != 4 {
+		t.Fatalf("expected 4 conversations, got %d", len(synced.List))
+	}
+	if synced.List[0].RemoteID == "" {
+		t.Errorf("expected local conversation to get a remote ID")
+	}
+	if len(synced.List[2].History) != 4 {
+		t.Errorf("expected remote changes to be pulled, got %d messages", len(synced.List[2].History))
+	}
+	if web := synced.List[3]; web.RemoteID != "web" || web.ID != 4 || len(web.History) != 2 {
+		t.Errorf("unexpected pulled conversation: %+v", web)
+	}
+
+	pushed := chats["older"]["chat"].(map[string]interface{})["messages"].([]interface{})
+	if len(pushed) != 6 {
+		t.Errorf("expected local changes to be pushed, got %d messages", len(pushed))
+	}
+}
+
+// TestSyncConversationsDryRun checks that a dry run changes nothing
+func TestSyncConversationsDryRun(t *testing.T) {
+	chats := map[string]map[string]interface{}{
+		"web": remoteChat("web", "started in the browser", time.Now(), "hi", "hello"),
+	}
+	server := newFakeOpenWebUI(t, chats)
+
+	local := Conversations{List: []Conversation{{ID: 1, Query: "cli only", History: threeTurnHistory()}}}
+	synced, report, err := syncConversations(local, openwebui.NewClient(server.URL, "key"), syncOptions{DryRun: true, Pull: true, Push: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Created) != 1 || len(report.Pulled) != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(chats) != 1 || len(synced.List) != 1 || synced.List[0].RemoteID != "" {
+		t.Errorf("expected dry run not to change anything")
+	}
+}
+
+// TestSyncConversationsPartial checks conversations created before an error
+// keep their remote ID
+func TestSyncConversationsPartial(t *testing.T) {
+	created := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v1/chats/list" {
+			fmt.Fprint(w, "[]")
+			return
+		}
+		if created++; created > 1 {
+			http.Error(w, "database is locked", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"id": "new-1", "updated_at": %d, "chat": {}}`, time.Now().Unix())
+	}))
+	defer server.Close()
+
+	local := Conversations{List: []Conversation{
+		{ID: 1, Query: "first", History: threeTurnHistory()},
+		{ID: 2, Query: "second", History: threeTurnHistory()},
+	}}
+	synced, report, err := syncConversations(local, openwebui.NewClient(server.URL, "key"), syncOptions{Pull: true, Push: true})
+	if err == nil {
+		t.Fatalf("expected an error for the failed create")
+	}
+	if len(synced.List) != 2 || synced.List[0].RemoteID != "new-1" || synced.List[1].RemoteID != "" {
+		t.Errorf("expected the first conversation to keep its remote ID, got %+v", synced.List)
+	}
+	if len(report.Created) != 1 {
+		t.Errorf("expected only the created conversation in the report, got %+v", report.Created)
+	}
+}
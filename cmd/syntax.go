@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/redact"
+	"github.com/spf13/viper"
+)
+
+// defaultValidationRetries is used when validation.max_retries is not set
+const defaultValidationRetries = 2
+
+const fixPrompt = `Your reply contains code with errors:
+
+%s
+
+Fix these errors and reply again in full, in the same format as before.`
+
+// validationRetries returns how often the model is asked to fix code that
+// does not parse
+func validationRetries() int {
+	if !viper.IsSet("validation.max_retries") {
+		return defaultValidationRetries
+	}
+	return viper.GetInt("validation.max_retries")
+}
+
+// revalidate checks a response and, while check fails, sends the errors back
+// to the model and asks for a corrected reply. It returns the last response
+// and the error of the last check.
+func revalidate(apiHost, apiKey, model string, redactor *redact.Redactor, prompt, response string, check func(response string) error) (string, error) {
+	history := []map[string]string{{"role": "user", "content": prompt}}
+	for attempt := 1; ; attempt++ {
+		err := check(response)
+		if err == nil || attempt > validationRetries() {
+			return response, err
+		}
+
+		fmt.Fprintf(os.Stderr, "⚠️ Generated code has errors, asking for a fix (attempt %d of %d)\n", attempt, validationRetries())
+		history = append(history,
+			map[string]string{"role": "assistant", "content": response},
+			map[string]string{"role": "user", "content": fmt.Sprintf(fixPrompt, err)},
+		)
+		response, err = sendRedactedQuery(apiHost, apiKey, model, redactor, history)
+		if err != nil {
+			return response, fmt.Errorf("asking for a fix: %w", err)
+		}
+	}
+}
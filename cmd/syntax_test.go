@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+)
+
+// TestReviseFileReprompts checks a revised file that does not parse is sent
+// back to the model with the parse error
+func TestReviseFileReprompts(t *testing.T) {
+	replies := []string{"```yaml\nimage: [nginx\n```", "```yaml\nimage: nginx:1.27\n```"}
+	requests := 0
+	var lastPrompt string
+	server := newFakeModel(t, func(prompt string) string {
+		lastPrompt = prompt
+		requests++
+		return replies[requests-1]
+	})
+
+	revised, err := reviseFile(server.URL, "key", "model", "image: nginx\n", filetype.Kubernetes, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revised != "image: nginx:1.27\n" {
+		t.Errorf("unexpected revised file %q", revised)
+	}
+	if requests != 2 || !strings.Contains(lastPrompt, "invalid YAML on line") {
+		t.Errorf("expected a second request with the parse error, got %d requests and %q", requests, lastPrompt)
+	}
+}
+
+// TestReviseFileInvalid checks a file that never parses is not returned
+func TestReviseFileInvalid(t *testing.T) {
+	requests := 0
+	server := newFakeModel(t, func(string) string {
+		requests++
+		return "```yaml\nimage: [nginx\n```"
+	})
+
+	if _, err := reviseFile(server.URL, "key", "model", "image: nginx\n", filetype.Kubernetes, ""); err == nil {
+		t.Errorf("expected an error for a revised file that does not parse")
+	}
+	if requests != defaultValidationRetries+1 {
+		t.Errorf("expected %d requests, got %d", defaultValidationRetries+1, requests)
+	}
+}
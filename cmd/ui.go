@@ -0,0 +1,485 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+)
+
+// uiMode is the current input mode of the UI
+type uiMode int
+
+const (
+	uiBrowse uiMode = iota
+	uiSearch
+	uiTag
+	uiConfirmDelete
+)
+
+const uiHelp = "↑/↓ select • pgup/pgdn scroll • / search • c continue • f fork • t tag • e export • d delete • a all projects • q quit"
+
+// Styles used by the UI
+var (
+	uiSelectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	uiDimStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	uiPaneStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("8"))
+	uiStatusStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+)
+
+// uiModel is the Bubble Tea model for browsing conversations
+type uiModel struct {
+	conversations []Conversation
+	visible       []Conversation
+	cursor        int
+	showAll       bool
+	project       string
+	query         string
+	mode          uiMode
+	input         textinput.Model
+	transcript    viewport.Model
+	renderedID    int
+	width         int
+	height        int
+	status        string
+	continueID    int
+}
+
+var uiCmd = &cobra.Command{
+	Use:   "ui",
+	Short: "Browse and manage conversations in a terminal UI",
+	Long: `Opens a full-screen terminal UI listing the stored conversations with a rendered
+transcript of the selected conversation. Conversations can be searched, continued,
+forked, tagged, exported to Markdown and deleted.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		program := tea.NewProgram(newUIModel(), tea.WithAltScreen())
+		final, err := program.Run()
+		if err != nil {
+			fmt.Println("Error running UI:", err)
+			os.Exit(1)
+		}
+
+		// Continuing a conversation hands over to the chat REPL
+		if m, ok := final.(uiModel); ok && m.continueID > 0 {
+			runChat(m.continueID, "")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(uiCmd)
+}
+
+// newUIModel loads the conversations of the current project
+func newUIModel() uiModel {
+	input := textinput.New()
+	input.CharLimit = 200
+
+	m := uiModel{
+		project:    currentProject(),
+		input:      input,
+		transcript: viewport.New(0, 0),
+		renderedID: -1,
+	}
+	m.reload()
+	return m
+}
+
+func (m uiModel) Init() tea.Cmd {
+	return nil
+}
+
+// reload reads the conversation store and reapplies the filters
+func (m *uiModel) reload() {
+	m.conversations = loadAllConversations().List
+	m.applyFilter()
+}
+
+// applyFilter updates the visible conversations for the project scope and
+// search query
+func (m *uiModel) applyFilter() {
+	list := m.conversations
+	if !m.showAll {
+		list = conversationsForProject(list, m.project)
+	}
+
+	if strings.TrimSpace(m.query) != "" {
+		visible := []Conversation{}
+		for _, hit := range searchConversations(list, m.query, searchFilter{}) {
+			visible = append(visible, hit.Conversation)
+		}
+		m.visible = visible
+	} else {
+		m.visible = append([]Conversation{}, list...)
+		sort.SliceStable(m.visible, func(a, b int) bool {
+			return m.visible[a].UpdatedAt.After(m.visible[b].UpdatedAt)
+		})
+	}
+
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	m.renderedID = -1
+	m.renderTranscript()
+}
+
+// selected returns the selected conversation
+func (m uiModel) selected() (Conversation, bool) {
+	if len(m.visible) == 0 {
+		return Conversation{}, false
+	}
+	return m.visible[m.cursor], true
+}
+
+// selectID moves the cursor to a conversation ID if it is visible
+func (m *uiModel) selectID(id int) {
+	for i, conv := range m.visible {
+		if conv.ID == id {
+			m.cursor = i
+			m.renderTranscript()
+			return
+		}
+	}
+}
+
+// paneWidths returns the widths of the list and transcript panes
+func (m uiModel) paneWidths() (int, int) {
+	listWidth := m.width * 2 / 5
+	if listWidth < 20 {
+		listWidth = 20
+	}
+	transcriptWidth := m.width - listWidth - 4
+	if transcriptWidth < 20 {
+		transcriptWidth = 20
+	}
+	return listWidth, transcriptWidth
+}
+
+// renderTranscript renders the selected conversation with glamour
+func (m *uiModel) renderTranscript() {
+	conv, ok := m.selected()
+	if !ok {
+		m.transcript.SetContent("No conversations.")
+		m.renderedID = -1
+		return
+	}
+	if conv.ID == m.renderedID || m.width == 0 {
+		return
+	}
+
+	_, width := m.paneWidths()
+	renderer, err := glamour.NewTermRenderer(
+		glamour.WithAutoStyle(),
+		glamour.WithWordWrap(width-4),
+	)
+	content := conversationMarkdown(conv)
+	if err == nil {
+		if rendered, err := renderer.Render(content); err == nil {
+			content = rendered
+		}
+	}
+
+	m.transcript.SetContent(content)
+	m.transcript.GotoTop()
+	m.renderedID = conv.ID
+}
+
+func (m uiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		_, transcriptWidth := m.paneWidths()
+		m.transcript.Width = transcriptWidth
+		m.transcript.Height = m.height - 6
+		m.renderedID = -1
+		m.renderTranscript()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch m.mode {
+		case uiSearch, uiTag:
+			return m.updateInput(msg)
+		case uiConfirmDelete:
+			return m.updateConfirmDelete(msg)
+		}
+		return m.updateBrowse(msg)
+	}
+	return m, nil
+}
+
+// updateBrowse handles keys while browsing the list
+func (m uiModel) updateBrowse(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.status = ""
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+			m.renderTranscript()
+		}
+
+	case "down", "j":
+		if m.cursor < len(m.visible)-1 {
+			m.cursor++
+			m.renderTranscript()
+		}
+
+	case "pgup", "pgdown", "ctrl+u", "ctrl+d":
+		var cmd tea.Cmd
+		m.transcript, cmd = m.transcript.Update(msg)
+		return m, cmd
+
+	case "/":
+		m.mode = uiSearch
+		m.input.Prompt = "search: "
+		m.input.SetValue(m.query)
+		return m, m.input.Focus()
+
+	case "esc":
+		if m.query != "" {
+			m.query = ""
+			m.applyFilter()
+		}
+
+	case "a":
+		m.showAll = !m.showAll
+		m.applyFilter()
+
+	case "c":
+		if conv, ok := m.selected(); ok {
+			m.continueID = conv.ID
+			return m, tea.Quit
+		}
+
+	case "f":
+		if conv, ok := m.selected(); ok {
+			fork, err := forkConversation(conv.ID, 0)
+			if err != nil {
+				m.status = fmt.Sprintf("Error forking: %v", err)
+				break
+			}
+			m.reload()
+			m.selectID(fork.ID)
+			m.status = fmt.Sprintf("🍴 Forked %d into %d", conv.ID, fork.ID)
+		}
+
+	case "t":
+		if conv, ok := m.selected(); ok {
+			m.mode = uiTag
+			m.input.Prompt = "tags: "
+			m.input.SetValue(strings.Join(conv.Tags, ", "))
+			return m, m.input.Focus()
+		}
+
+	case "e":
+		if conv, ok := m.selected(); ok {
+			path := fmt.Sprintf("conversation-%d.md", conv.ID)
+			if err := os.WriteFile(path, []byte(conversationMarkdown(conv)), 0600); err != nil {
+				m.status = fmt.Sprintf("Error exporting: %v", err)
+			} else {
+				m.status = "📄 Exported to " + path
+			}
+		}
+
+	case "d":
+		if _, ok := m.selected(); ok {
+			m.mode = uiConfirmDelete
+		}
+	}
+	return m, nil
+}
+
+// updateInput handles keys while typing a search or tags
+func (m uiModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.mode = uiBrowse
+		m.input.Blur()
+		return m, nil
+
+	case "enter":
+		value := m.input.Value()
+		mode := m.mode
+		m.mode = uiBrowse
+		m.input.Blur()
+
+		if mode == uiSearch {
+			m.query = value
+			m.cursor = 0
+			m.applyFilter()
+			return m, nil
+		}
+
+		conv, ok := m.selected()
+		if !ok {
+			return m, nil
+		}
+		if err := setConversationTags(conv.ID, strings.Split(value, ",")); err != nil {
+			m.status = fmt.Sprintf("Error tagging: %v", err)
+			return m, nil
+		}
+		m.reload()
+		m.selectID(conv.ID)
+		m.status = "🏷️ Tags updated"
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// updateConfirmDelete handles the delete confirmation
+func (m uiModel) updateConfirmDelete(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	m.mode = uiBrowse
+	conv, ok := m.selected()
+	if !ok || (msg.String() != "y" && msg.String() != "Y") {
+		m.status = "Delete cancelled"
+		return m, nil
+	}
+
+	if err := deleteSingleConversation(conv.ID); err != nil {
+		m.status = fmt.Sprintf("Error deleting: %v", err)
+		return m, nil
+	}
+	m.reload()
+	m.status = fmt.Sprintf("🗑️ Deleted conversation %d", conv.ID)
+	return m, nil
+}
+
+func (m uiModel) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	listWidth, transcriptWidth := m.paneWidths()
+	height := m.height - 4
+
+	scope := projectName(m.project)
+	if m.showAll {
+		scope = "all projects"
+	}
+	header := fmt.Sprintf("📝 Conversations (%s)", scope)
+	if m.query != "" {
+		header += fmt.Sprintf(" matching %q", m.query)
+	}
+
+	list := uiPaneStyle.Width(listWidth).Height(height).Render(m.listView(listWidth, height))
+	transcript := uiPaneStyle.Width(transcriptWidth).Height(height).Render(m.transcript.View())
+
+	footer := uiDimStyle.Render(uiHelp)
+	switch m.mode {
+	case uiSearch, uiTag:
+		footer = m.input.View()
+	case uiConfirmDelete:
+		if conv, ok := m.selected(); ok {
+			footer = fmt.Sprintf("Delete conversation %d? (y/N)", conv.ID)
+		}
+	default:
+		if m.status != "" {
+			footer = uiStatusStyle.Render(m.status)
+		}
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		header,
+		lipgloss.JoinHorizontal(lipgloss.Top, list, transcript),
+		footer,
+	)
+}
+
+// listView renders the visible conversations, scrolled to the cursor
+func (m uiModel) listView(width, height int) string {
+	if len(m.visible) == 0 {
+		return uiDimStyle.Render("No conversations found.")
+	}
+
+	start := 0
+	if m.cursor >= height {
+		start = m.cursor - height + 1
+	}
+
+	lines := []string{}
+	for i := start; i < len(m.visible) && i < start+height; i++ {
+		conv := m.visible[i]
+		line := fmt.Sprintf("%d: %s", conv.ID, strings.Join(strings.Fields(conv.Query), " "))
+		if conv.Pinned {
+			line = "📌 " + line
+		}
+		if len(conv.Tags) > 0 {
+			line += " [" + strings.Join(conv.Tags, ", ") + "]"
+		}
+		if runes := []rune(line); len(runes) > width-2 {
+			line = string(runes[:width-3]) + "…"
+		}
+
+		if i == m.cursor {
+			lines = append(lines, uiSelectedStyle.Render("▸ "+line))
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// setConversationTags replaces the tags of a stored conversation
+func setConversationTags(id int, tags []string) error {
+	conversations := loadAllConversations()
+	for i, conv := range conversations.List {
+		if conv.ID == id {
+			conversations.List[i].Tags = mergeTags(nil, tags)
+			return writeConversations(conversations)
+		}
+	}
+	return fmt.Errorf("conversation not found")
+}
+
+// conversationMarkdown formats a conversation transcript as Markdown
+func conversationMarkdown(conv Conversation) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", strings.Join(strings.Fields(conv.Query), " "))
+
+	details := []string{fmt.Sprintf("Conversation ID: %d", conv.ID)}
+	if conv.Model != "" {
+		details = append(details, "Model: "+conv.Model)
+	}
+	if !conv.UpdatedAt.IsZero() {
+		details = append(details, "Updated: "+conv.UpdatedAt.Format("2006-01-02 15:04"))
+	}
+	if len(conv.Tags) > 0 {
+		details = append(details, "Tags: "+strings.Join(conv.Tags, ", "))
+	}
+	if len(conv.Attachments) > 0 {
+		details = append(details, "Attachments: "+strings.Join(conv.Attachments, ", "))
+	}
+	if conv.ParentID != 0 {
+		details = append(details, fmt.Sprintf("Forked from %d at turn %d", conv.ParentID, conv.ForkedAt))
+	}
+	fmt.Fprintf(&b, "_%s_\n\n", strings.Join(details, " • "))
+
+	if conv.Summary != "" {
+		fmt.Fprintf(&b, "## 📋 Summary\n\n%s\n\n---\n\n", strings.TrimSpace(conv.Summary))
+	}
+
+	for _, msg := range conv.History {
+		role := "🧑 You"
+		if msg["role"] == "assistant" {
+			role = "🤖 Assistant"
+		}
+		fmt.Fprintf(&b, "## %s\n\n%s\n\n", role, strings.TrimSpace(msg["content"]))
+	}
+	return b.String()
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func pressKey(m tea.Model, key string) tea.Model {
+	var msg tea.KeyMsg
+	switch key {
+	case "enter":
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	default:
+		msg = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	}
+	m, _ = m.Update(msg)
+	return m
+}
+
+// TestUIModelSearchAndDelete checks searching and deleting from the UI
+func TestUIModelSearchAndDelete(t *testing.T) {
+	useTempSessionFile(t)
+	saveConversation(threeTurnHistory(), 0, "one", "gemma:2b", nil, nil)
+	saveConversation([]map[string]string{
+		{"role": "user", "content": "ingress 502"},
+		{"role": "assistant", "content": "check the backend pods"},
+	}, 0, "ingress 502", "gemma:2b", nil, nil)
+
+	var m tea.Model = newUIModel()
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	if got := len(m.(uiModel).visible); got != 2 {
+		t.Fatalf("expected 2 visible conversations, got %d", got)
+	}
+
+	m = pressKey(m, "/")
+	for _, r := range "ingress" {
+		m = pressKey(m, string(r))
+	}
+	m = pressKey(m, "enter")
+	if visible := m.(uiModel).visible; len(visible) != 1 || visible[0].ID != 2 {
+		t.Fatalf("expected search to show conversation 2, got %v", visible)
+	}
+	if !strings.Contains(m.View(), "ingress") {
+		t.Errorf("expected view to show the conversation")
+	}
+
+	m = pressKey(m, "d")
+	m = pressKey(m, "y")
+	if got := len(loadAllConversations().List); got != 1 {
+		t.Errorf("expected 1 conversation after delete, got %d", got)
+	}
+}
+
+// TestConversationMarkdown checks the exported transcript
+func TestConversationMarkdown(t *testing.T) {
+	md := conversationMarkdown(Conversation{ID: 3, Query: "one", Tags: []string{"incident"}, History: threeTurnHistory()})
+	for _, expected := range []string{"# one", "Conversation ID: 3", "Tags: incident", "## 🤖 Assistant", "reply three"} {
+		if !strings.Contains(md, expected) {
+			t.Errorf("expected markdown to contain %q", expected)
+		}
+	}
+}
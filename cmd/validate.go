@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/ruanbekker/devops-ai-cli/internal/validate"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var validateFilePath string
+var validateRecursive bool
+var validateKubernetesVersion string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate -f <file>",
+	Short: "Check files parse and Kubernetes manifests match their schemas",
+	Long: `Checks that YAML, JSON, Terraform/HCL, shell and Dockerfiles parse, and validates every
+document in Kubernetes manifests against the resource schemas of a Kubernetes
+version, without a cluster. Schemas are downloaded once and cached, so later
+runs work offline. Custom resources without a schema are skipped.
+Pass a directory (with --recursive for sub directories) or a glob to validate
+every supported file.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		// Read from stdin with -f - or when input is piped without -f
+		if validateFilePath == "" && stdinIsPiped() {
+			validateFilePath = "-"
+		}
+		if validateFilePath == "" {
+			fmt.Println("Error: Please specify a file with -f")
+			os.Exit(1)
+		}
+
+		files := []string{validateFilePath}
+		if validateFilePath != "-" && isBatchTarget(validateFilePath) {
+			found, err := discoverFiles(validateFilePath, validateRecursive)
+			if err != nil {
+				fmt.Printf("Error finding files: %v\n", err)
+				os.Exit(1)
+			}
+			files = found
+		}
+
+		schemas := newSchemas(validateKubernetesVersion)
+		failed := 0
+		for _, path := range files {
+			if !printValidation(path, schemas) {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			fmt.Printf("\n❌ %d of %d file(s) failed validation\n", failed, len(files))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	validateCmd.Flags().StringVarP(&validateFilePath, "file", "f", "", "File, directory or glob to validate, or - for stdin")
+	validateCmd.Flags().BoolVarP(&validateRecursive, "recursive", "r", false, "Include sub directories when validating a directory")
+	validateCmd.Flags().StringVar(&validateKubernetesVersion, "kubernetes-version", "", "Kubernetes version to validate against, e.g. 1.30.0 (default from config, or 1.31.0)")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// newSchemas returns the Kubernetes schemas from config, using version
+// when it is set
+func newSchemas(version string) *validate.Schemas {
+	if version == "" {
+		version = viper.GetString("validation.kubernetes.version")
+	}
+	return validate.NewSchemas(
+		version,
+		viper.GetString("validation.kubernetes.schema_location"),
+		viper.GetString("validation.kubernetes.cache_dir"),
+	)
+}
+
+// kubernetesValidationEnabled reports whether revised Kubernetes manifests
+// are validated against their schemas
+func kubernetesValidationEnabled() bool {
+	return !viper.IsSet("validation.kubernetes.enabled") || viper.GetBool("validation.kubernetes.enabled")
+}
+
+// validateContent checks content parses and, for Kubernetes manifests,
+// matches the schemas. It returns the problems found and the resources that
+// were skipped for lack of a schema. The error is set when schemas cannot
+// be loaded.
+func validateContent(schemas *validate.Schemas, kind filetype.Kind, name, content string) ([]string, []string, error) {
+	if kind != filetype.Kubernetes {
+		if err := validate.Syntax(kind, name, content); err != nil {
+			return []string{err.Error()}, nil, nil
+		}
+		return nil, nil, nil
+	}
+
+	report, err := schemas.Validate(content)
+	var syntaxErr *validate.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return []string{err.Error()}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	problems := []string{}
+	for _, e := range report.Errors {
+		problems = append(problems, e.Error())
+	}
+	return problems, report.Skipped, nil
+}
+
+// printValidation validates a file and prints the result. It returns false
+// when the file has problems or cannot be validated.
+func printValidation(path string, schemas *validate.Schemas) bool {
+	name, detectName := path, path
+	if path == "-" {
+		name, detectName = "stdin", ""
+	}
+
+	content, err := readOptimizeInput(path)
+	if err != nil {
+		fmt.Printf("❌ %s: %v\n", name, err)
+		return false
+	}
+
+	kind := filetype.Detect(detectName, content)
+	if kind != filetype.Kubernetes && !validate.HasSyntaxCheck(kind) {
+		fmt.Printf("⏭️ %s: no checks for %s\n", name, kind)
+		return true
+	}
+
+	problems, skipped, err := validateContent(schemas, kind, path, content)
+	if err != nil {
+		fmt.Printf("❌ %s: %v\n", name, err)
+		return false
+	}
+	for _, resource := range skipped {
+		fmt.Printf("⚠️ %s: no schema for %s, skipped\n", name, resource)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("❌ %s (%s)\n", name, kind)
+		for _, problem := range problems {
+			fmt.Printf("   %s\n", problem)
+		}
+		return false
+	}
+	fmt.Printf("✅ %s (%s)\n", name, kind)
+	return true
+}
+
+// checkRevision checks a revised file parses and, for Kubernetes manifests,
+// matches the resource schemas. When the schemas cannot be loaded, e.g.
+// offline without a cache, only a warning is printed.
+func checkRevision(kind filetype.Kind, content string) error {
+	if kind == filetype.Kubernetes && !kubernetesValidationEnabled() {
+		return validate.Syntax(kind, "revised", content)
+	}
+
+	problems, _, err := validateContent(newSchemas(""), kind, "revised", content)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️ Skipping schema validation: %v\n", err)
+		return validate.Syntax(kind, "revised", content)
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"github.com/spf13/viper"
+)
+
+// TestCheckRevision checks revised manifests are validated against the
+// schemas and other files only for syntax
+func TestCheckRevision(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type": "object", "properties": {"spec": {"type": "object", "additionalProperties": false, "properties": {"replicas": {"type": "integer"}}}}}`))
+	}))
+	defer server.Close()
+
+	viper.Set("validation.kubernetes.schema_location", server.URL+"/{version}/{file}")
+	viper.Set("validation.kubernetes.cache_dir", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("validation.kubernetes.schema_location", "")
+		viper.Set("validation.kubernetes.cache_dir", "")
+	})
+
+	manifest := "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 2\n"
+	if err := checkRevision(filetype.Kubernetes, manifest); err != nil {
+		t.Errorf("unexpected error for a valid manifest: %v", err)
+	}
+
+	err := checkRevision(filetype.Kubernetes, manifest+"  replica: 2\n")
+	if err == nil || !strings.Contains(err.Error(), "line 5: Deployment spec.replica: unknown field") {
+		t.Errorf("expected an unknown field error, got %v", err)
+	}
+
+	if err := checkRevision(filetype.JSON, `{"a": }`); err == nil {
+		t.Errorf("expected a syntax error for invalid JSON")
+	}
+}
+
+// TestReviseFileRedactedSecret checks the placeholders of a redacted Secret
+// are put back before the revised manifest is checked against the schema
+func TestReviseFileRedactedSecret(t *testing.T) {
+	schemas := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"type": "object", "properties": {"data": {"type": "object", "additionalProperties": {"type": "string"}}}}`))
+	}))
+	defer schemas.Close()
+
+	viper.Set("redaction.enabled", true)
+	viper.Set("validation.kubernetes.schema_location", schemas.URL+"/{version}/{file}")
+	viper.Set("validation.kubernetes.cache_dir", t.TempDir())
+	t.Cleanup(func() {
+		viper.Set("redaction.enabled", false)
+		viper.Set("validation.kubernetes.schema_location", "")
+		viper.Set("validation.kubernetes.cache_dir", "")
+	})
+
+	// The model replies with the manifest as it received it, placeholder included
+	placeholder := regexp.MustCompile(`\[REDACTED_[A-Z0-9_]+\]`)
+	requests := 0
+	server := newFakeModel(t, func(prompt string) string {
+		requests++
+		return "```yaml\napiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: " + placeholder.FindString(prompt) + "\n```"
+	})
+	defer server.Close()
+
+	content := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: c3VwZXJzZWNyZXQ=\n"
+	revised, err := reviseFile(server.URL, "key", "model", content, filetype.Kubernetes, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if revised != content {
+		t.Errorf("expected the secret to be restored, got %q", revised)
+	}
+	if requests != 1 {
+		t.Errorf("expected a single request, got %d", requests)
+	}
+}
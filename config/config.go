@@ -38,10 +38,23 @@ func InitConfig() {
 	viper.SetDefault("openwebui.api_key", "")
   viper.SetDefault("openwebui.model", "gemma:2b")
   viper.SetDefault("debug", false)
+	viper.SetDefault("input.max_size", "1MB")
+	viper.SetDefault("optimize.concurrency", 4)
+	viper.SetDefault("chunking.max_tokens", 6000)
+	viper.SetDefault("validation.max_retries", 2)
+	viper.SetDefault("validation.kubernetes.enabled", true)
+	viper.SetDefault("validation.kubernetes.version", "1.31.0")
+	viper.SetDefault("validation.kubernetes.schema_location", "")
+	viper.SetDefault("validation.kubernetes.cache_dir", "")
+	viper.SetDefault("redaction.enabled", true)
+	viper.SetDefault("redaction.restore", false)
+	viper.SetDefault("redaction.entropy", true)
+	viper.SetDefault("history.encryption.mode", "")
+	viper.SetDefault("history.encryption.key_env", "DEVOPSCLI_HISTORY_KEY")
 
   // Read config file if available
 	if err := viper.ReadInConfig(); err != nil {
-		fmt.Println("Using default config, no config file found.")
+		fmt.Fprintln(os.Stderr, "Using default config, no config file found.")
 	}
 
 	if viper.GetBool("debug") {
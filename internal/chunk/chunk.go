@@ -0,0 +1,291 @@
+package chunk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Kind selects how content is split into chunks
+type Kind int
+
+const (
+	// Text is split on blank lines
+	Text Kind = iota
+	// YAML is split on --- document separators
+	YAML
+	// Terraform is split between top level blocks
+	Terraform
+	// Log is split on minute boundaries of the line timestamps
+	Log
+)
+
+// Chunk is a part of the input with the lines it covers
+type Chunk struct {
+	Content   string
+	StartLine int
+	EndLine   int
+	// Window is the time range of a log chunk
+	Window string
+}
+
+// Label describes where the chunk comes from
+func (c Chunk) Label() string {
+	label := fmt.Sprintf("lines %d-%d", c.StartLine, c.EndLine)
+	if c.Window != "" {
+		label += ", " + c.Window
+	}
+	return label
+}
+
+// segment is a unit that is never split unless it is larger than a chunk
+type segment struct {
+	lines []string
+	start int
+	times []time.Time
+}
+
+// EstimateTokens approximates the number of tokens in s, using the common
+// estimate of four characters per token
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Split splits content into chunks of at most maxTokens, breaking on the
+// semantic boundaries of kind where possible
+func Split(content string, kind Kind, maxTokens int) []Chunk {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if maxTokens <= 0 || EstimateTokens(content) <= maxTokens {
+		return []Chunk{newChunk(segment{lines: lines, start: 1})}
+	}
+
+	var segments []segment
+	switch kind {
+	case YAML:
+		segments = splitYAML(lines)
+	case Terraform:
+		segments = splitTerraform(lines)
+	case Log:
+		segments = splitLog(lines)
+	default:
+		segments = splitParagraphs(lines)
+	}
+	return pack(segments, maxTokens)
+}
+
+// newChunk builds a chunk from a segment
+func newChunk(s segment) Chunk {
+	c := Chunk{
+		Content:   strings.Join(s.lines, "\n"),
+		StartLine: s.start,
+		EndLine:   s.start + len(s.lines) - 1,
+	}
+
+	var first, last time.Time
+	for _, t := range s.times {
+		if t.IsZero() {
+			continue
+		}
+		if first.IsZero() {
+			first = t
+		}
+		last = t
+	}
+	if !first.IsZero() {
+		c.Window = first.Format("15:04:05") + "-" + last.Format("15:04:05")
+	}
+	return c
+}
+
+// pack joins consecutive segments into chunks of at most maxTokens.
+// Segments larger than maxTokens are split on lines.
+func pack(segments []segment, maxTokens int) []Chunk {
+	chunks := []Chunk{}
+	current := segment{}
+	size := 0
+
+	flush := func() {
+		if len(current.lines) > 0 {
+			chunks = append(chunks, newChunk(current))
+		}
+		current = segment{}
+		size = 0
+	}
+
+	for _, s := range segments {
+		if size > 0 && size+segmentSize(s) > maxTokens {
+			flush()
+		}
+		for i, line := range s.lines {
+			lineSize := EstimateTokens(line + "\n")
+			// Only reached mid-segment when the segment alone is too large
+			if size > 0 && size+lineSize > maxTokens {
+				flush()
+			}
+			if len(current.lines) == 0 {
+				current.start = s.start + i
+			}
+			current.lines = append(current.lines, line)
+			if s.times != nil {
+				current.times = append(current.times, s.times[i])
+			}
+			size += lineSize
+		}
+	}
+	flush()
+	return chunks
+}
+
+// segmentSize returns the estimated tokens of a segment
+func segmentSize(s segment) int {
+	size := 0
+	for _, line := range s.lines {
+		size += EstimateTokens(line + "\n")
+	}
+	return size
+}
+
+// splitWhen starts a new segment before every line where boundary is true
+func splitWhen(lines []string, boundary func(i int) bool) []segment {
+	segments := []segment{}
+	current := segment{start: 1}
+	for i, line := range lines {
+		if i > 0 && boundary(i) {
+			segments = append(segments, current)
+			current = segment{start: i + 1}
+		}
+		current.lines = append(current.lines, line)
+	}
+	return append(segments, current)
+}
+
+// splitYAML splits before every --- document separator
+func splitYAML(lines []string) []segment {
+	return splitWhen(lines, func(i int) bool {
+		return strings.HasPrefix(strings.TrimRight(lines[i], " \r"), "---")
+	})
+}
+
+// splitTerraform splits between top level blocks by tracking brace depth
+func splitTerraform(lines []string) []segment {
+	depths := make([]int, len(lines))
+	depth := 0
+	for i, line := range lines {
+		depths[i] = depth
+		depth += braceDelta(line)
+		if depth < 0 {
+			depth = 0
+		}
+	}
+
+	return splitWhen(lines, func(i int) bool {
+		trimmed := strings.TrimSpace(lines[i])
+		if depths[i] != 0 || trimmed == "" || strings.HasPrefix(trimmed, "}") {
+			return false
+		}
+		// Keep comments with the block that follows them
+		previous := strings.TrimSpace(lines[i-1])
+		return !strings.HasPrefix(previous, "#") && !strings.HasPrefix(previous, "//")
+	})
+}
+
+// braceDelta returns the change in brace depth for a line, ignoring braces
+// in strings and comments
+func braceDelta(line string) int {
+	delta := 0
+	inString := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inString && c == '\\':
+			i++
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '#' || (c == '/' && i+1 < len(line) && line[i+1] == '/'):
+			return delta
+		case c == '{':
+			delta++
+		case c == '}':
+			delta--
+		}
+	}
+	return delta
+}
+
+// splitParagraphs splits after blank lines
+func splitParagraphs(lines []string) []segment {
+	return splitWhen(lines, func(i int) bool {
+		return strings.TrimSpace(lines[i-1]) == "" && strings.TrimSpace(lines[i]) != ""
+	})
+}
+
+// Timestamp formats recognised at the start of log lines
+var logTimestamps = []struct {
+	pattern *regexp.Regexp
+	layout  string
+}{
+	{regexp.MustCompile(`^\[?(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2})`), "2006-01-02 15:04:05"},
+	{regexp.MustCompile(`^\[?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2})`), "Jan _2 15:04:05"},
+	{regexp.MustCompile(`^\[?(\d{2}:\d{2}:\d{2})`), "15:04:05"},
+}
+
+// LogTime returns the timestamp at the start of a log line
+func LogTime(line string) (time.Time, bool) {
+	for _, ts := range logTimestamps {
+		m := ts.pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		t, err := time.Parse(ts.layout, strings.Replace(m[1], "T", " ", 1))
+		if err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// LooksLikeLog reports whether most lines start with a timestamp
+func LooksLikeLog(content string) bool {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) < 2 {
+		return false
+	}
+	stamped := 0
+	for _, line := range lines {
+		if _, ok := LogTime(line); ok {
+			stamped++
+		}
+	}
+	return stamped*2 > len(lines)
+}
+
+// splitLog splits where the timestamp moves to a new minute. Lines without a
+// timestamp, such as stack traces, stay with the line before them.
+func splitLog(lines []string) []segment {
+	times := make([]time.Time, len(lines))
+	for i, line := range lines {
+		if t, ok := LogTime(line); ok {
+			times[i] = t
+		}
+	}
+
+	segments := splitWhen(lines, func(i int) bool {
+		if times[i].IsZero() {
+			return false
+		}
+		for j := i - 1; j >= 0; j-- {
+			if !times[j].IsZero() {
+				return !times[j].Truncate(time.Minute).Equal(times[i].Truncate(time.Minute))
+			}
+		}
+		return false
+	})
+
+	for i := range segments {
+		start := segments[i].start - 1
+		segments[i].times = times[start : start+len(segments[i].lines)]
+	}
+	return segments
+}
@@ -0,0 +1,106 @@
+package chunk
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestSplitSmallInput checks input under the limit stays in one chunk
+func TestSplitSmallInput(t *testing.T) {
+	chunks := Split("a: 1\nb: 2\n", YAML, 100)
+	if len(chunks) != 1 || chunks[0].StartLine != 1 || chunks[0].EndLine != 2 {
+		t.Errorf("expected a single chunk, got %+v", chunks)
+	}
+}
+
+// TestSplitYAML checks documents are not split across chunks
+func TestSplitYAML(t *testing.T) {
+	doc := "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n"
+	content := fmt.Sprintf(doc, "one") + "---\n" + fmt.Sprintf(doc, "two") + "---\n" + fmt.Sprintf(doc, "three")
+
+	chunks := Split(content, YAML, 20)
+	if len(chunks) != 3 {
+		t.Fatalf("expected 3 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	for i, name := range []string{"one", "two", "three"} {
+		if strings.Count(chunks[i].Content, "kind: ConfigMap") != 1 || !strings.Contains(chunks[i].Content, "name: "+name) {
+			t.Errorf("expected chunk %d to hold document %s, got %q", i, name, chunks[i].Content)
+		}
+	}
+	if chunks[1].StartLine != 5 || chunks[1].EndLine != 9 {
+		t.Errorf("unexpected lines for chunk 2: %s", chunks[1].Label())
+	}
+}
+
+// TestSplitTerraform checks blocks with nested braces stay together
+func TestSplitTerraform(t *testing.T) {
+	content := `# The bucket
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs-${var.env}"
+  tags = {
+    Name = "logs"
+  }
+}
+
+variable "env" {
+  default = "prod"
+}
+`
+	chunks := Split(content, Terraform, 35)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	if !strings.HasPrefix(chunks[0].Content, "# The bucket") || !strings.HasSuffix(strings.TrimSpace(chunks[0].Content), "}") {
+		t.Errorf("expected the comment and resource in the first chunk, got %q", chunks[0].Content)
+	}
+	if !strings.HasPrefix(chunks[1].Content, `variable "env"`) {
+		t.Errorf("expected the variable in the second chunk, got %q", chunks[1].Content)
+	}
+}
+
+// TestSplitLog checks logs are split on minute boundaries and continuation
+// lines stay with their entry
+func TestSplitLog(t *testing.T) {
+	content := `2025-03-10T10:01:05Z ERROR request failed
+    at handler.go:42
+2025-03-10T10:01:59Z INFO retrying
+2025-03-10T10:02:01Z INFO recovered
+2025-03-10T10:02:30Z INFO healthy
+`
+	if !LooksLikeLog(content) {
+		t.Errorf("expected content to look like a log")
+	}
+
+	chunks := Split(content, Log, 30)
+	if len(chunks) != 2 {
+		t.Fatalf("expected 2 chunks, got %d: %+v", len(chunks), chunks)
+	}
+	if chunks[0].Label() != "lines 1-3, 10:01:05-10:01:59" {
+		t.Errorf("unexpected label %q", chunks[0].Label())
+	}
+	if !strings.Contains(chunks[0].Content, "handler.go:42") {
+		t.Errorf("expected the stack trace with its entry, got %q", chunks[0].Content)
+	}
+}
+
+// TestSplitOversizedSegment checks a segment larger than the limit is
+// split on lines
+func TestSplitOversizedSegment(t *testing.T) {
+	lines := []string{}
+	for i := 0; i < 40; i++ {
+		lines = append(lines, fmt.Sprintf("line %02d", i))
+	}
+	chunks := Split(strings.Join(lines, "\n"), Text, 20)
+	if len(chunks) < 2 {
+		t.Fatalf("expected several chunks, got %d", len(chunks))
+	}
+	for _, c := range chunks {
+		if EstimateTokens(c.Content) > 20 {
+			t.Errorf("chunk %s is larger than the limit", c.Label())
+		}
+	}
+	if chunks[len(chunks)-1].EndLine != 40 {
+		t.Errorf("expected the last chunk to end on line 40, got %d", chunks[len(chunks)-1].EndLine)
+	}
+}
@@ -0,0 +1,103 @@
+package encryption
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// IsEncrypted reports whether data is an age encrypted file, armored or binary
+func IsEncrypted(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	return bytes.HasPrefix(trimmed, []byte(armor.Header)) ||
+		bytes.HasPrefix(trimmed, []byte("age-encryption.org/v1"))
+}
+
+// Encrypt encrypts plaintext to the recipients as an armored age file
+func Encrypt(plaintext []byte, recipients ...age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	armorWriter := armor.NewWriter(&buf)
+
+	w, err := age.Encrypt(armorWriter, recipients...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	if err := armorWriter.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decrypt decrypts an armored or binary age file with the identities
+func Decrypt(data []byte, identities ...age.Identity) ([]byte, error) {
+	var src io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(armor.Header)) {
+		src = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	}
+
+	r, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+// PassphraseKeys returns the recipient and identity derived from a passphrase
+func PassphraseKeys(passphrase string) (age.Recipient, age.Identity, error) {
+	if passphrase == "" {
+		return nil, nil, fmt.Errorf("passphrase is empty")
+	}
+
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recipient, identity, nil
+}
+
+// ParseIdentities parses one or more age identities (AGE-SECRET-KEY-1...),
+// ignoring blank lines and comments as in an age identity file
+func ParseIdentities(text string) ([]age.Identity, error) {
+	return age.ParseIdentities(strings.NewReader(text))
+}
+
+// ParseRecipient parses an age public key (age1...)
+func ParseRecipient(text string) (age.Recipient, error) {
+	return age.ParseX25519Recipient(strings.TrimSpace(text))
+}
+
+// RecipientsFor returns the recipients matching X25519 identities
+func RecipientsFor(identities []age.Identity) []age.Recipient {
+	recipients := []age.Recipient{}
+	for _, identity := range identities {
+		if x, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, x.Recipient())
+		}
+	}
+	return recipients
+}
+
+// GenerateIdentity creates a new age identity and returns the secret key
+// and its public recipient
+func GenerateIdentity() (string, string, error) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return "", "", err
+	}
+	return identity.String(), identity.Recipient().String(), nil
+}
@@ -0,0 +1,72 @@
+package encryption
+
+import (
+	"testing"
+)
+
+// TestPassphraseRoundTrip ensures data encrypted with a passphrase decrypts
+func TestPassphraseRoundTrip(t *testing.T) {
+	recipient, identity, err := PassphraseKeys("correct horse")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	encrypted, err := Encrypt([]byte(`{"conversations":[]}`), recipient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !IsEncrypted(encrypted) {
+		t.Errorf("expected output to be detected as encrypted")
+	}
+
+	plaintext, err := Decrypt(encrypted, identity)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(plaintext) != `{"conversations":[]}` {
+		t.Errorf("unexpected plaintext: %s", plaintext)
+	}
+
+	_, wrong, _ := PassphraseKeys("wrong")
+	if _, err := Decrypt(encrypted, wrong); err == nil {
+		t.Errorf("expected error when decrypting with the wrong passphrase")
+	}
+}
+
+// TestIdentityRoundTrip ensures data encrypted to an age recipient decrypts
+func TestIdentityRoundTrip(t *testing.T) {
+	secret, public, err := GenerateIdentity()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	identities, err := ParseIdentities("# created for a test\n" + secret + "\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	recipients := RecipientsFor(identities)
+	if len(recipients) != 1 {
+		t.Fatalf("expected 1 recipient, got %d", len(recipients))
+	}
+
+	recipient, err := ParseRecipient(public)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	encrypted, err := Encrypt([]byte("secret"), recipient)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	plaintext, err := Decrypt(encrypted, identities...)
+	if err != nil || string(plaintext) != "secret" {
+		t.Errorf("expected round trip, got %q (%v)", plaintext, err)
+	}
+}
+
+// TestIsEncrypted ensures plain JSON is not detected as encrypted
+func TestIsEncrypted(t *testing.T) {
+	if IsEncrypted([]byte(`{"conversations":[]}`)) {
+		t.Errorf("expected plain JSON not to be detected as encrypted")
+	}
+}
@@ -0,0 +1,223 @@
+package filetype
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Kind is the type of a code or configuration file
+type Kind int
+
+const (
+	Unknown Kind = iota
+	Kubernetes
+	HelmChart
+	HelmValues
+	DockerCompose
+	GitHubActions
+	Ansible
+	CloudFormation
+	YAML
+	JSON
+	Terraform
+	HCL
+	Dockerfile
+	Jenkinsfile
+	Makefile
+	Shell
+	Python
+)
+
+var kindNames = map[Kind]struct{ id, description string }{
+	Unknown:        {"unknown", "Unknown format"},
+	Kubernetes:     {"kubernetes", "Kubernetes YAML"},
+	HelmChart:      {"helm-chart", "Helm Chart.yaml"},
+	HelmValues:     {"helm-values", "Helm values file"},
+	DockerCompose:  {"docker-compose", "Docker Compose file"},
+	GitHubActions:  {"github-actions", "GitHub Actions workflow"},
+	Ansible:        {"ansible", "Ansible playbook"},
+	CloudFormation: {"cloudformation", "CloudFormation template"},
+	YAML:           {"yaml", "YAML file"},
+	JSON:           {"json", "JSON configuration"},
+	Terraform:      {"terraform", "Terraform script"},
+	HCL:            {"hcl", "HCL configuration"},
+	Dockerfile:     {"dockerfile", "Dockerfile"},
+	Jenkinsfile:    {"jenkinsfile", "Jenkinsfile"},
+	Makefile:       {"makefile", "Makefile"},
+	Shell:          {"shell", "Shell script"},
+	Python:         {"python", "Python script"},
+}
+
+// String returns a description of the kind for use in prompts
+func (k Kind) String() string {
+	return kindNames[k].description
+}
+
+// ID returns a short identifier for the kind, e.g. for config and reports
+func (k Kind) ID() string {
+	return kindNames[k].id
+}
+
+// IsYAML reports whether files of this kind are usually written in YAML
+func (k Kind) IsYAML() bool {
+	switch k {
+	case Kubernetes, HelmChart, HelmValues, DockerCompose, GitHubActions, Ansible, CloudFormation, YAML:
+		return true
+	}
+	return false
+}
+
+var (
+	topLevelKeyPattern  = regexp.MustCompile(`(?m)^["']?([A-Za-z][A-Za-z0-9_-]*)["']?\s*:`)
+	ansiblePlayPattern  = regexp.MustCompile(`(?m)^-\s+(?:name:.*\n\s+)?hosts:`)
+	ansibleTaskPattern  = regexp.MustCompile(`(?m)^\s+(?:-\s+)?(?:ansible\.builtin\.[a-z_]+|become|tasks|roles|handlers):`)
+	terraformPattern    = regexp.MustCompile(`(?m)^(?:resource|module|provider|variable|output|data|locals|terraform)(?:\s+"[^"]*")*\s*\{`)
+	dockerfilePattern   = regexp.MustCompile(`^(?:ARG\s+\S+\s*\n\s*)*FROM\s+\S+`)
+	cloudFormationTypes = regexp.MustCompile(`(?m)Type["']?\s*:\s*["']?AWS::`)
+	helmValuesPattern   = regexp.MustCompile(`^values(?:[-.][^/]*)?\.ya?ml$`)
+)
+
+// Detect returns the kind of a file from its name and content. Either may
+// be empty, e.g. for input piped on stdin.
+func Detect(name, content string) Kind {
+	if kind := detectByName(name); kind != Unknown {
+		return kind
+	}
+
+	ext := strings.ToLower(filepath.Ext(name))
+	switch ext {
+	case ".json":
+		return detectJSON(content)
+	case ".yaml", ".yml":
+		if kind := detectYAML(content); kind != Unknown {
+			return kind
+		}
+		return YAML
+	}
+	return detectByContent(content)
+}
+
+// detectByName detects kinds that are identified by their file name
+func detectByName(name string) Kind {
+	if name == "" || name == "-" {
+		return Unknown
+	}
+	base := strings.ToLower(filepath.Base(name))
+	ext := strings.ToLower(filepath.Ext(base))
+	slashed := filepath.ToSlash(strings.ToLower(name))
+
+	switch {
+	case base == "dockerfile" || base == "containerfile" || strings.HasPrefix(base, "dockerfile.") || ext == ".dockerfile":
+		return Dockerfile
+	case base == "jenkinsfile" || ext == ".jenkinsfile":
+		return Jenkinsfile
+	case base == "makefile" || base == "gnumakefile" || ext == ".mk":
+		return Makefile
+	case base == "chart.yaml" || base == "chart.yml":
+		return HelmChart
+	case helmValuesPattern.MatchString(base):
+		return HelmValues
+	case (strings.HasPrefix(base, "docker-compose") || strings.HasPrefix(base, "compose.")) && (ext == ".yaml" || ext == ".yml"):
+		return DockerCompose
+	case strings.Contains(slashed, ".github/workflows/") && (ext == ".yaml" || ext == ".yml"):
+		return GitHubActions
+	case ext == ".tf" || ext == ".tfvars":
+		return Terraform
+	case ext == ".hcl":
+		return HCL
+	case ext == ".sh" || ext == ".bash" || ext == ".zsh":
+		return Shell
+	case ext == ".py":
+		return Python
+	}
+	return Unknown
+}
+
+// detectJSON detects the kind of a JSON document
+func detectJSON(content string) Kind {
+	switch {
+	case strings.Contains(content, `"AWSTemplateFormatVersion"`) || cloudFormationTypes.MatchString(content):
+		return CloudFormation
+	case strings.Contains(content, `"apiVersion"`) && strings.Contains(content, `"kind"`):
+		return Kubernetes
+	}
+	return JSON
+}
+
+// detectYAML detects the kind of a YAML document from its top level keys
+func detectYAML(content string) Kind {
+	keys := map[string]bool{}
+	for _, m := range topLevelKeyPattern.FindAllStringSubmatch(content, -1) {
+		keys[m[1]] = true
+	}
+
+	switch {
+	case keys["AWSTemplateFormatVersion"] || (keys["Resources"] && cloudFormationTypes.MatchString(content)):
+		return CloudFormation
+	case keys["apiVersion"] && keys["kind"]:
+		return Kubernetes
+	case keys["apiVersion"] && keys["name"] && keys["version"]:
+		return HelmChart
+	case keys["on"] && keys["jobs"]:
+		return GitHubActions
+	case keys["services"]:
+		return DockerCompose
+	case ansiblePlayPattern.MatchString(content) || (strings.HasPrefix(strings.TrimSpace(content), "- ") && ansibleTaskPattern.MatchString(content)):
+		return Ansible
+	}
+	return Unknown
+}
+
+// detectByContent detects files without a telling name or extension
+func detectByContent(content string) Kind {
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" {
+		return Unknown
+	}
+
+	if strings.HasPrefix(trimmed, "#!") {
+		shebang := strings.SplitN(trimmed, "\n", 2)[0]
+		switch {
+		case strings.Contains(shebang, "python"):
+			return Python
+		case strings.Contains(shebang, "sh"):
+			return Shell
+		}
+	}
+
+	if (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)) {
+		return detectJSON(trimmed)
+	}
+	// Instructions are matched in upper case, so prose that starts with
+	// "From" is not taken for a Dockerfile
+	if dockerfilePattern.MatchString(withoutComments(trimmed)) {
+		return Dockerfile
+	}
+	if terraformPattern.MatchString(trimmed) {
+		return Terraform
+	}
+	if strings.HasPrefix(trimmed, "pipeline {") || strings.HasPrefix(trimmed, "node {") {
+		return Jenkinsfile
+	}
+	if kind := detectYAML(content); kind != Unknown {
+		return kind
+	}
+	if len(topLevelKeyPattern.FindAllString(content, 3)) >= 2 {
+		return YAML
+	}
+	return Unknown
+}
+
+// withoutComments drops leading # comment and blank lines
+func withoutComments(content string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return strings.Join(lines[i:], "\n")
+		}
+	}
+	return ""
+}
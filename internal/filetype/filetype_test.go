@@ -0,0 +1,54 @@
+package filetype
+
+import "testing"
+
+// TestDetect checks detection by file name and content
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    Kind
+	}{
+		{"deploy.yaml", "apiVersion: apps/v1\nkind: Deployment\n", Kubernetes},
+		{"config.yml", "log_level: debug\nport: 8080\n", YAML},
+		{"docker-compose.yml", "services:\n  web:\n    image: nginx\n", DockerCompose},
+		{"stack.yaml", "version: '3'\nservices:\n  web:\n    image: nginx\n", DockerCompose},
+		{".github/workflows/ci.yml", "name: ci\n", GitHubActions},
+		{"ci.yaml", "name: ci\non:\n  push:\njobs:\n  build:\n    runs-on: ubuntu-latest\n", GitHubActions},
+		{"site.yml", "- name: Web servers\n  hosts: web\n  tasks:\n    - ansible.builtin.apt:\n        name: nginx\n", Ansible},
+		{"charts/app/Chart.yaml", "apiVersion: v2\nname: app\nversion: 0.1.0\n", HelmChart},
+		{"charts/app/values-prod.yaml", "replicaCount: 3\n", HelmValues},
+		{"stack.yaml", "AWSTemplateFormatVersion: '2010-09-09'\nResources: {}\n", CloudFormation},
+		{"stack.json", `{"Resources": {"Bucket": {"Type": "AWS::S3::Bucket"}}}`, CloudFormation},
+		{"package.json", `{"name": "app"}`, JSON},
+		{"main.tf", "", Terraform},
+		{"terragrunt.hcl", "", HCL},
+		{"Dockerfile", "", Dockerfile},
+		{"build/Dockerfile.prod", "", Dockerfile},
+		{"Jenkinsfile", "", Jenkinsfile},
+		{"Makefile", "", Makefile},
+		{"deploy", "#!/usr/bin/env bash\nset -e\n", Shell},
+		{"tool", "#!/usr/bin/env python3\nprint('hi')\n", Python},
+		{"-", "# syntax=docker/dockerfile:1\nARG VERSION=1\nFROM alpine:${VERSION}\n", Dockerfile},
+		{"-", "From what I see the pod restarts every few minutes.\n", Unknown},
+		{"-", "resource \"aws_s3_bucket\" \"logs\" {\n}\n", Terraform},
+		{"-", "apiVersion: v1\nkind: Service\n", Kubernetes},
+		{"README.md", "# Title\n", Unknown},
+	}
+
+	for _, tt := range tests {
+		if got := Detect(tt.name, tt.content); got != tt.want {
+			t.Errorf("Detect(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestKindNames checks descriptions and identifiers
+func TestKindNames(t *testing.T) {
+	if Kubernetes.String() != "Kubernetes YAML" || Kubernetes.ID() != "kubernetes" {
+		t.Errorf("unexpected names for Kubernetes: %s, %s", Kubernetes, Kubernetes.ID())
+	}
+	if !DockerCompose.IsYAML() || Dockerfile.IsYAML() {
+		t.Errorf("unexpected IsYAML results")
+	}
+}
@@ -0,0 +1,248 @@
+package openwebui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Client talks to the OpenWebUI chats API
+type Client struct {
+	Host       string
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// Message is a single chat message
+type Message struct {
+	Role    string
+	Content string
+}
+
+// ChatSummary is a chat as returned by the chat list endpoint
+type ChatSummary struct {
+	ID        string
+	Title     string
+	UpdatedAt time.Time
+}
+
+// Chat is a chat with its messages
+type Chat struct {
+	ID        string
+	Title     string
+	Model     string
+	Tags      []string
+	Messages  []Message
+	UpdatedAt time.Time
+}
+
+// NewClient returns a client for an OpenWebUI host
+func NewClient(host, apiKey string) *Client {
+	return &Client{Host: strings.TrimRight(host, "/"), APIKey: apiKey, HTTPClient: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Wire formats of the OpenWebUI chats API
+type chatResponse struct {
+	ID        string          `json:"id"`
+	Title     string          `json:"title"`
+	Chat      json.RawMessage `json:"chat"`
+	UpdatedAt int64           `json:"updated_at"`
+}
+
+type wireMessage struct {
+	ID          string   `json:"id"`
+	ParentID    *string  `json:"parentId"`
+	ChildrenIDs []string `json:"childrenIds"`
+	Role        string   `json:"role"`
+	Content     string   `json:"content"`
+	Timestamp   int64    `json:"timestamp"`
+	Models      []string `json:"models,omitempty"`
+	Model       string   `json:"model,omitempty"`
+}
+
+type wireChat struct {
+	Title    string        `json:"title"`
+	Models   []string      `json:"models"`
+	Tags     []string      `json:"tags"`
+	Messages []wireMessage `json:"messages"`
+	History  struct {
+		Messages  map[string]wireMessage `json:"messages"`
+		CurrentID string                 `json:"currentId"`
+	} `json:"history"`
+	Timestamp int64 `json:"timestamp"`
+}
+
+// ListChats returns every chat of the user, following pagination
+func (c *Client) ListChats() ([]ChatSummary, error) {
+	chats := []ChatSummary{}
+	seen := map[string]bool{}
+	for page := 1; ; page++ {
+		var list []chatResponse
+		if err := c.do("GET", fmt.Sprintf("/api/v1/chats/list?page=%d", page), nil, &list); err != nil {
+			return nil, err
+		}
+
+		added := 0
+		for _, item := range list {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+			added++
+			chats = append(chats, ChatSummary{ID: item.ID, Title: item.Title, UpdatedAt: time.Unix(item.UpdatedAt, 0)})
+		}
+		// Servers without pagination return the same list for every page
+		if added == 0 {
+			return chats, nil
+		}
+	}
+}
+
+// GetChat returns a chat with its messages
+func (c *Client) GetChat(id string) (Chat, error) {
+	var resp chatResponse
+	if err := c.do("GET", "/api/v1/chats/"+id, nil, &resp); err != nil {
+		return Chat{}, err
+	}
+	return decodeChat(resp)
+}
+
+// CreateChat creates a new chat and returns it with its ID
+func (c *Client) CreateChat(chat Chat) (Chat, error) {
+	var resp chatResponse
+	if err := c.do("POST", "/api/v1/chats/new", map[string]interface{}{"chat": encodeChat(chat)}, &resp); err != nil {
+		return Chat{}, err
+	}
+	return decodeChat(resp)
+}
+
+// UpdateChat replaces the messages of an existing chat
+func (c *Client) UpdateChat(id string, chat Chat) (Chat, error) {
+	var resp chatResponse
+	if err := c.do("POST", "/api/v1/chats/"+id, map[string]interface{}{"chat": encodeChat(chat)}, &resp); err != nil {
+		return Chat{}, err
+	}
+	return decodeChat(resp)
+}
+
+// do sends a JSON request and decodes the JSON response into out
+func (c *Client) do(method, path string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.Host+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: unexpected status %s: %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
+
+// encodeChat converts a chat to the OpenWebUI chat object, linking the
+// messages into a single branch
+func encodeChat(chat Chat) wireChat {
+	var wc wireChat
+	wc.Title = chat.Title
+	wc.Tags = chat.Tags
+	if wc.Tags == nil {
+		wc.Tags = []string{}
+	}
+	if chat.Model != "" {
+		wc.Models = []string{chat.Model}
+	}
+	wc.Timestamp = time.Now().UnixMilli()
+	wc.History.Messages = map[string]wireMessage{}
+
+	now := time.Now().Unix()
+	var parent *string
+	for i, msg := range chat.Messages {
+		id := fmt.Sprintf("devopscli-%d", i+1)
+		wm := wireMessage{ID: id, ParentID: parent, ChildrenIDs: []string{}, Role: msg.Role, Content: msg.Content, Timestamp: now}
+		if msg.Role == "assistant" {
+			wm.Model = chat.Model
+		} else if chat.Model != "" {
+			wm.Models = []string{chat.Model}
+		}
+		if i+1 < len(chat.Messages) {
+			wm.ChildrenIDs = []string{fmt.Sprintf("devopscli-%d", i+2)}
+		}
+		wc.Messages = append(wc.Messages, wm)
+		wc.History.Messages[id] = wm
+		wc.History.CurrentID = id
+		parentID := id
+		parent = &parentID
+	}
+	if wc.Messages == nil {
+		wc.Messages = []wireMessage{}
+	}
+	return wc
+}
+
+// decodeChat converts an OpenWebUI chat response. The current branch is
+// read from the message history when present, otherwise from the flat
+// message list.
+func decodeChat(resp chatResponse) (Chat, error) {
+	chat := Chat{ID: resp.ID, Title: resp.Title, UpdatedAt: time.Unix(resp.UpdatedAt, 0)}
+	if len(resp.Chat) == 0 {
+		return chat, nil
+	}
+
+	var wc wireChat
+	if err := json.Unmarshal(resp.Chat, &wc); err != nil {
+		return chat, err
+	}
+	if chat.Title == "" {
+		chat.Title = wc.Title
+	}
+	if len(wc.Models) > 0 {
+		chat.Model = wc.Models[0]
+	}
+	chat.Tags = wc.Tags
+
+	messages := []wireMessage{}
+	if id := wc.History.CurrentID; id != "" && len(wc.History.Messages) > 0 {
+		for id != "" {
+			msg, ok := wc.History.Messages[id]
+			if !ok {
+				break
+			}
+			messages = append([]wireMessage{msg}, messages...)
+			if msg.ParentID == nil {
+				break
+			}
+			id = *msg.ParentID
+		}
+	} else {
+		messages = wc.Messages
+	}
+
+	for _, msg := range messages {
+		chat.Messages = append(chat.Messages, Message{Role: msg.Role, Content: msg.Content})
+	}
+	return chat, nil
+}
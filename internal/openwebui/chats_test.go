@@ -0,0 +1,140 @@
+package openwebui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer is an in-memory stand-in for the OpenWebUI chats API
+type fakeServer struct {
+	mu    sync.Mutex
+	chats map[string]chatResponse
+	next  int
+}
+
+func newFakeServer(t *testing.T) (*fakeServer, *httptest.Server) {
+	t.Helper()
+	fake := &fakeServer{chats: map[string]chatResponse{}}
+	server := httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeServer) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer key" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/api/v1/chats/list":
+		list := []chatResponse{}
+		if r.URL.Query().Get("page") == "1" {
+			for _, chat := range f.chats {
+				list = append(list, chatResponse{ID: chat.ID, Title: chat.Title, UpdatedAt: chat.UpdatedAt})
+			}
+		}
+		json.NewEncoder(w).Encode(list)
+
+	case r.Method == "GET":
+		chat, ok := f.chats[strings.TrimPrefix(r.URL.Path, "/api/v1/chats/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(chat)
+
+	case r.Method == "POST":
+		var body struct {
+			Chat json.RawMessage `json:"chat"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		var wc wireChat
+		json.Unmarshal(body.Chat, &wc)
+
+		id := strings.TrimPrefix(r.URL.Path, "/api/v1/chats/")
+		if id == "new" {
+			f.next++
+			id = fmt.Sprintf("chat-%d", f.next)
+		}
+		chat := chatResponse{ID: id, Title: wc.Title, Chat: body.Chat, UpdatedAt: time.Now().Unix()}
+		f.chats[id] = chat
+		json.NewEncoder(w).Encode(chat)
+	}
+}
+
+// TestCreateAndGetChat checks a chat survives a round trip
+func TestCreateAndGetChat(t *testing.T) {
+	_, server := newFakeServer(t)
+	client := NewClient(server.URL, "key")
+
+	created, err := client.CreateChat(Chat{
+		Title: "What is Kubernetes?",
+		Model: "gemma:2b",
+		Messages: []Message{
+			{Role: "user", Content: "What is Kubernetes?"},
+			{Role: "assistant", Content: "A container orchestrator."},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if created.ID == "" {
+		t.Fatalf("expected chat ID")
+	}
+
+	list, err := client.ListChats()
+	if err != nil || len(list) != 1 {
+		t.Fatalf("expected 1 chat, got %v (%v)", list, err)
+	}
+
+	chat, err := client.GetChat(created.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if chat.Model != "gemma:2b" || len(chat.Messages) != 2 || chat.Messages[1].Content != "A container orchestrator." {
+		t.Errorf("unexpected chat: %+v", chat)
+	}
+}
+
+// TestDecodeChatFollowsCurrentBranch checks that only the current branch
+// of an edited chat is returned
+func TestDecodeChatFollowsCurrentBranch(t *testing.T) {
+	raw := `{
+		"title": "branched",
+		"messages": [],
+		"history": {
+			"currentId": "a2",
+			"messages": {
+				"u1": {"id": "u1", "parentId": null, "role": "user", "content": "hi"},
+				"a1": {"id": "a1", "parentId": "u1", "role": "assistant", "content": "old reply"},
+				"a2": {"id": "a2", "parentId": "u1", "role": "assistant", "content": "new reply"}
+			}
+		}
+	}`
+
+	chat, err := decodeChat(chatResponse{ID: "x", Chat: json.RawMessage(raw)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(chat.Messages) != 2 || chat.Messages[1].Content != "new reply" {
+		t.Errorf("expected current branch, got %+v", chat.Messages)
+	}
+}
+
+// TestUnauthorized checks that API errors are returned
+func TestUnauthorized(t *testing.T) {
+	_, server := newFakeServer(t)
+	if _, err := NewClient(server.URL, "wrong").ListChats(); err == nil {
+		t.Errorf("expected error for wrong API key")
+	}
+}
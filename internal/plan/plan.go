@@ -0,0 +1,329 @@
+package plan
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+)
+
+// Action is what a plan does to a resource
+type Action string
+
+const (
+	Create  Action = "create"
+	Update  Action = "update"
+	Replace Action = "replace"
+	Destroy Action = "destroy"
+)
+
+// Actions in report order
+var Actions = []Action{Create, Update, Replace, Destroy}
+
+// ErrNotPlan is returned for JSON that is not terraform show -json output
+var ErrNotPlan = errors.New("not a Terraform plan, create one with: terraform plan -out tfplan && terraform show -json tfplan > plan.json")
+
+// Plan is the summary of a Terraform plan
+type Plan struct {
+	TerraformVersion string
+	Changes          []Change
+}
+
+// Change is a managed resource the plan creates, updates, replaces or
+// destroys
+type Change struct {
+	Address string
+	Type    string
+	Action  Action
+	// Attributes are the top level attributes that change, for updates and
+	// replacements. Attributes only known after apply are left out.
+	Attributes []string
+	before     map[string]interface{}
+	after      map[string]interface{}
+}
+
+// Risk is a change that needs a closer look before applying
+type Risk struct {
+	Address  string
+	Severity rules.Severity
+	Message  string
+}
+
+// rawPlan is the part of terraform show -json output that is read
+type rawPlan struct {
+	FormatVersion    string `json:"format_version"`
+	TerraformVersion string `json:"terraform_version"`
+	ResourceChanges  []struct {
+		Address string `json:"address"`
+		Mode    string `json:"mode"`
+		Type    string `json:"type"`
+		Change  struct {
+			Actions      []string               `json:"actions"`
+			Before       map[string]interface{} `json:"before"`
+			After        map[string]interface{} `json:"after"`
+			ReplacePaths [][]interface{}        `json:"replace_paths"`
+		} `json:"change"`
+	} `json:"resource_changes"`
+}
+
+// Parse reads the output of terraform show -json. Data sources and
+// resources without changes are left out.
+func Parse(data []byte) (Plan, error) {
+	var raw rawPlan
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Plan{}, fmt.Errorf("reading plan: %w", err)
+	}
+	if raw.FormatVersion == "" {
+		return Plan{}, ErrNotPlan
+	}
+
+	p := Plan{TerraformVersion: raw.TerraformVersion}
+	for _, rc := range raw.ResourceChanges {
+		action, ok := toAction(rc.Change.Actions)
+		if rc.Mode == "data" || !ok {
+			continue
+		}
+		change := Change{
+			Address: rc.Address,
+			Type:    rc.Type,
+			Action:  action,
+			before:  rc.Change.Before,
+			after:   rc.Change.After,
+		}
+		if action == Update || action == Replace {
+			change.Attributes = changedAttributes(rc.Change.Before, rc.Change.After, rc.Change.ReplacePaths)
+		}
+		p.Changes = append(p.Changes, change)
+	}
+	return p, nil
+}
+
+// toAction maps the actions of a resource change to an Action. No-op and
+// read changes have none.
+func toAction(actions []string) (Action, bool) {
+	switch strings.Join(actions, ",") {
+	case "create":
+		return Create, true
+	case "update":
+		return Update, true
+	case "delete":
+		return Destroy, true
+	case "delete,create", "create,delete":
+		return Replace, true
+	}
+	return "", false
+}
+
+// changedAttributes returns the top level attributes that differ between
+// before and after, marking those that force a replacement
+func changedAttributes(before, after map[string]interface{}, replacePaths [][]interface{}) []string {
+	forces := map[string]bool{}
+	for _, path := range replacePaths {
+		if len(path) > 0 {
+			if name, ok := path[0].(string); ok {
+				forces[name] = true
+			}
+		}
+	}
+
+	names := []string{}
+	for name := range after {
+		if forces[name] || !reflect.DeepEqual(before[name], after[name]) {
+			names = append(names, name)
+		}
+	}
+	for name := range forces {
+		if _, ok := after[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		if forces[name] {
+			names[i] = name + " (forces replacement)"
+		}
+	}
+	return names
+}
+
+// Count returns the number of changes with an action
+func (p Plan) Count(action Action) int {
+	count := 0
+	for _, c := range p.Changes {
+		if c.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+var (
+	databasePattern = regexp.MustCompile(`^(aws_(db_instance|rds_cluster|rds_cluster_instance|dynamodb_table|elasticache_|redshift_cluster|docdb_cluster|neptune_cluster|opensearch_domain|elasticsearch_domain)|google_(sql_|spanner_|bigtable_|firestore_)|azurerm_(mssql_|mysql_|postgresql_|sql_|cosmosdb_|redis_cache))`)
+	iamPattern      = regexp.MustCompile(`^(aws_iam_|google_.*iam_|azurerm_role_|kubernetes_(cluster_)?role)`)
+	openRanges      = map[string]bool{"0.0.0.0/0": true, "::/0": true, "*": true, "Internet": true, "Any": true}
+	broadRoles      = map[string]bool{"roles/owner": true, "roles/editor": true}
+)
+
+// Risks returns the changes that need a closer look, most severe first:
+// databases that are destroyed or replaced, ingress opened to the internet
+// and IAM changes
+func Risks(p Plan) []Risk {
+	risks := []Risk{}
+	for _, c := range p.Changes {
+		if databasePattern.MatchString(c.Type) {
+			switch c.Action {
+			case Destroy:
+				risks = append(risks, Risk{c.Address, rules.High, "database is destroyed"})
+			case Replace:
+				risks = append(risks, Risk{c.Address, rules.High, "database is replaced, its data is lost unless it is restored from a snapshot"})
+			}
+		}
+
+		if c.Action != Destroy {
+			existing := map[string]bool{}
+			for _, rule := range openIngress(c.Type, c.before) {
+				existing[rule] = true
+			}
+			for _, rule := range openIngress(c.Type, c.after) {
+				if !existing[rule] {
+					risks = append(risks, Risk{c.Address, rules.High, "opens ingress " + rule})
+				}
+			}
+		}
+
+		if iamPattern.MatchString(c.Type) {
+			switch {
+			case c.Action != Destroy && allowsAllActions(c.after):
+				risks = append(risks, Risk{c.Address, rules.High, "IAM policy is " + pastTense[c.Action] + " and allows all actions"})
+			case c.Action != Destroy && broadRoles[stringValue(c.after, "role")]:
+				risks = append(risks, Risk{c.Address, rules.High, fmt.Sprintf("IAM binding is %s for %s", pastTense[c.Action], stringValue(c.after, "role"))})
+			default:
+				risks = append(risks, Risk{c.Address, rules.Medium, "IAM resource is " + pastTense[c.Action]})
+			}
+		}
+	}
+
+	sort.SliceStable(risks, func(i, j int) bool {
+		return risks[i].Severity > risks[j].Severity
+	})
+	return risks
+}
+
+// pastTense describes what happened to a resource for each action
+var pastTense = map[Action]string{
+	Create:  "created",
+	Update:  "updated",
+	Replace: "replaced",
+	Destroy: "destroyed",
+}
+
+// openIngress describes the ingress rules of a firewall resource that are
+// open to any address, e.g. "on port 22 from 0.0.0.0/0"
+func openIngress(resourceType string, values map[string]interface{}) []string {
+	if values == nil {
+		return nil
+	}
+
+	open := []string{}
+	add := func(rule map[string]interface{}, ports string, keys ...string) {
+		for _, key := range keys {
+			for _, source := range stringList(rule[key]) {
+				if openRanges[source] {
+					open = append(open, fmt.Sprintf("on %s from %s", ports, source))
+				}
+			}
+		}
+	}
+
+	switch resourceType {
+	case "aws_security_group":
+		for _, rule := range mapList(values["ingress"]) {
+			add(rule, portRange(rule["from_port"], rule["to_port"]), "cidr_blocks", "ipv6_cidr_blocks")
+		}
+	case "aws_security_group_rule":
+		if stringValue(values, "type") == "ingress" {
+			add(values, portRange(values["from_port"], values["to_port"]), "cidr_blocks", "ipv6_cidr_blocks")
+		}
+	case "aws_vpc_security_group_ingress_rule":
+		add(values, portRange(values["from_port"], values["to_port"]), "cidr_ipv4", "cidr_ipv6")
+	case "google_compute_firewall":
+		if !strings.EqualFold(stringValue(values, "direction"), "EGRESS") {
+			add(values, "all allowed ports", "source_ranges")
+		}
+	case "azurerm_network_security_rule":
+		if strings.EqualFold(stringValue(values, "direction"), "Inbound") && strings.EqualFold(stringValue(values, "access"), "Allow") {
+			add(values, "port "+stringValue(values, "destination_port_range"), "source_address_prefix")
+		}
+	}
+	return open
+}
+
+// portRange describes a from and to port
+func portRange(from, to interface{}) string {
+	f, _ := from.(float64)
+	t, _ := to.(float64)
+	switch {
+	case f == 0 && (t == 0 || t == 65535):
+		return "all ports"
+	case f == t:
+		return fmt.Sprintf("port %d", int(f))
+	}
+	return fmt.Sprintf("ports %d-%d", int(f), int(t))
+}
+
+// allowsAllActions reports whether a policy document in values allows
+// Action "*"
+func allowsAllActions(values map[string]interface{}) bool {
+	for _, value := range values {
+		policy, ok := value.(string)
+		if !ok {
+			continue
+		}
+		compact := strings.Join(strings.Fields(policy), "")
+		if strings.Contains(compact, `"Effect":"Allow"`) && (strings.Contains(compact, `"Action":"*"`) || strings.Contains(compact, `"Action":["*"]`)) {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue returns a string attribute, or "" when it is not a string
+func stringValue(values map[string]interface{}, key string) string {
+	s, _ := values[key].(string)
+	return s
+}
+
+// stringList returns a string or a list of strings as a list
+func stringList(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		list := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// mapList returns the objects in a list attribute
+func mapList(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	list := []map[string]interface{}{}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			list = append(list, m)
+		}
+	}
+	return list
+}
@@ -0,0 +1,152 @@
+package plan
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+)
+
+const examplePlan = `{
+  "format_version": "1.2",
+  "terraform_version": "1.9.5",
+  "resource_changes": [
+    {
+      "address": "aws_db_instance.main",
+      "mode": "managed",
+      "type": "aws_db_instance",
+      "change": {"actions": ["delete"], "before": {"identifier": "main"}, "after": null}
+    },
+    {
+      "address": "aws_instance.web",
+      "mode": "managed",
+      "type": "aws_instance",
+      "change": {
+        "actions": ["delete", "create"],
+        "before": {"ami": "ami-1", "instance_type": "t3.micro", "id": "i-1"},
+        "after": {"ami": "ami-2", "instance_type": "t3.small"},
+        "replace_paths": [["ami"]]
+      }
+    },
+    {
+      "address": "aws_security_group.web",
+      "mode": "managed",
+      "type": "aws_security_group",
+      "change": {
+        "actions": ["update"],
+        "before": {"name": "web", "ingress": [{"from_port": 443, "to_port": 443, "cidr_blocks": ["0.0.0.0/0"]}]},
+        "after": {"name": "web", "ingress": [
+          {"from_port": 443, "to_port": 443, "cidr_blocks": ["0.0.0.0/0"]},
+          {"from_port": 22, "to_port": 22, "cidr_blocks": ["0.0.0.0/0"]}
+        ]}
+      }
+    },
+    {
+      "address": "aws_iam_role_policy.admin",
+      "mode": "managed",
+      "type": "aws_iam_role_policy",
+      "change": {
+        "actions": ["create"],
+        "before": null,
+        "after": {"policy": "{\"Statement\": [{\"Effect\": \"Allow\", \"Action\": \"*\", \"Resource\": \"*\"}]}"}
+      }
+    },
+    {
+      "address": "aws_s3_bucket.logs",
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "change": {"actions": ["no-op"], "before": {"bucket": "logs"}, "after": {"bucket": "logs"}}
+    },
+    {
+      "address": "data.aws_ami.ubuntu",
+      "mode": "data",
+      "type": "aws_ami",
+      "change": {"actions": ["read"], "before": null, "after": {}}
+    }
+  ]
+}`
+
+// TestParse checks the actions and changed attributes of a plan
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(examplePlan))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.TerraformVersion != "1.9.5" {
+		t.Errorf("unexpected version %q", p.TerraformVersion)
+	}
+
+	want := map[string]Action{
+		"aws_db_instance.main":      Destroy,
+		"aws_instance.web":          Replace,
+		"aws_security_group.web":    Update,
+		"aws_iam_role_policy.admin": Create,
+	}
+	if len(p.Changes) != len(want) {
+		t.Fatalf("expected %d changes, got %+v", len(want), p.Changes)
+	}
+	for _, c := range p.Changes {
+		if want[c.Address] != c.Action {
+			t.Errorf("expected %s to %s, got %s", c.Address, want[c.Address], c.Action)
+		}
+	}
+
+	if got := p.Changes[1].Attributes; !reflect.DeepEqual(got, []string{"ami (forces replacement)", "instance_type"}) {
+		t.Errorf("unexpected replaced attributes %q", got)
+	}
+	if got := p.Changes[2].Attributes; !reflect.DeepEqual(got, []string{"ingress"}) {
+		t.Errorf("unexpected updated attributes %q", got)
+	}
+	if p.Count(Destroy) != 1 || p.Count(Update) != 1 {
+		t.Errorf("unexpected counts")
+	}
+}
+
+// TestParseNotPlan checks other JSON is rejected
+func TestParseNotPlan(t *testing.T) {
+	if _, err := Parse([]byte(`{"resource": {}}`)); !errors.Is(err, ErrNotPlan) {
+		t.Errorf("expected ErrNotPlan, got %v", err)
+	}
+	if _, err := Parse([]byte(`not json`)); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+}
+
+// TestRisks checks the database destroy, the new open ingress rule and the
+// IAM policy are flagged, and the ingress rule that was already open is not
+func TestRisks(t *testing.T) {
+	p, err := Parse([]byte(examplePlan))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Risk{
+		{"aws_db_instance.main", rules.High, "database is destroyed"},
+		{"aws_security_group.web", rules.High, "opens ingress on port 22 from 0.0.0.0/0"},
+		{"aws_iam_role_policy.admin", rules.High, "IAM policy is created and allows all actions"},
+	}
+	if got := Risks(p); !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected risks %+v", got)
+	}
+}
+
+// TestOpenIngress checks the firewall resources of other providers
+func TestOpenIngress(t *testing.T) {
+	tests := []struct {
+		resourceType string
+		values       map[string]interface{}
+		want         int
+	}{
+		{"aws_security_group_rule", map[string]interface{}{"type": "ingress", "from_port": 0.0, "to_port": 65535.0, "cidr_blocks": []interface{}{"0.0.0.0/0"}}, 1},
+		{"aws_security_group_rule", map[string]interface{}{"type": "egress", "cidr_blocks": []interface{}{"0.0.0.0/0"}}, 0},
+		{"aws_vpc_security_group_ingress_rule", map[string]interface{}{"cidr_ipv6": "::/0", "from_port": 80.0, "to_port": 80.0}, 1},
+		{"google_compute_firewall", map[string]interface{}{"direction": "INGRESS", "source_ranges": []interface{}{"10.0.0.0/8"}}, 0},
+		{"azurerm_network_security_rule", map[string]interface{}{"direction": "Inbound", "access": "Allow", "source_address_prefix": "*", "destination_port_range": "3389"}, 1},
+	}
+	for _, tt := range tests {
+		if got := openIngress(tt.resourceType, tt.values); len(got) != tt.want {
+			t.Errorf("expected %d open rules for %s, got %q", tt.want, tt.resourceType, got)
+		}
+	}
+}
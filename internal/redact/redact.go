@@ -0,0 +1,370 @@
+package redact
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// PlaceholderPrefix starts every placeholder inserted by a Redactor
+const PlaceholderPrefix = "[REDACTED_"
+
+// Longer bracketed text in a stream is never held back as a placeholder
+const maxPlaceholderLength = 64
+
+// Entropy checks only consider tokens of at least this length
+const minEntropyLength = 24
+
+// Tokens at or above this Shannon entropy (bits per character) are treated
+// as secrets. Hex strings such as digests and commit hashes stay below it.
+const entropyThreshold = 4.0
+
+// Rule finds secrets with a regular expression. When Group is set only that
+// capture group is replaced.
+type Rule struct {
+	Kind    string
+	Pattern *regexp.Regexp
+	Group   int
+}
+
+// Finding is a secret that was replaced with a placeholder
+type Finding struct {
+	Kind        string
+	Placeholder string
+}
+
+// Redactor replaces secrets with placeholders. The same secret always gets
+// the same placeholder so redacted text stays consistent across messages.
+// A Redactor is safe for concurrent use.
+type Redactor struct {
+	mu           sync.Mutex
+	rules        []Rule
+	entropy      bool
+	placeholders map[string]string
+	secrets      map[string]string
+	counts       map[string]int
+	findings     []Finding
+}
+
+var placeholderPattern = regexp.MustCompile(`\[REDACTED_[A-Z0-9_]+\]`)
+
+// DefaultRules detect common credentials
+var DefaultRules = []Rule{
+	{Kind: "private-key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{Kind: "aws-access-key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Kind: "aws-secret-key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?[ \t]*[:=][ \t]*["']?([A-Za-z0-9/+=]{40})`), Group: 1},
+	{Kind: "github-token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Kind: "slack-token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{Kind: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]+`)},
+	{Kind: "bearer-token", Pattern: regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9\-._~+/]{16,}=*)`), Group: 1},
+	{Kind: "url-password", Pattern: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s@/]+)@`), Group: 1},
+	// Kubernetes env entries such as "- name: DB_PASSWORD" followed by "value: ..."
+	{Kind: "env-secret", Pattern: regexp.MustCompile(`(?i)name:\s*["']?[A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_?KEY)[A-Z0-9_]*["']?\s*\n\s*value:\s*["']?([^\s"'#]+)`), Group: 1},
+	// Assignments in .env files, YAML, JSON and shell scripts
+	{Kind: "password", Pattern: regexp.MustCompile(`(?i)[A-Za-z0-9_.-]*(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)["']?[ \t]*[:=][ \t]*["']?([^\s"'#,;}]+)`), Group: 1},
+}
+
+// New returns a Redactor using the rules. Entropy checks catch high entropy
+// tokens that no rule matches.
+func New(rules []Rule, entropy bool) *Redactor {
+	return &Redactor{
+		rules:        rules,
+		entropy:      entropy,
+		placeholders: map[string]string{},
+		secrets:      map[string]string{},
+		counts:       map[string]int{},
+	}
+}
+
+// CustomRules compiles extra patterns. Patterns with a capture group only
+// redact the first group.
+func CustomRules(patterns []string) ([]Rule, error) {
+	rules := []Rule{}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		group := 0
+		if re.NumSubexp() > 0 {
+			group = 1
+		}
+		rules = append(rules, Rule{Kind: "custom", Pattern: re, Group: group})
+	}
+	return rules, nil
+}
+
+// Redact replaces the secrets in text with placeholders
+func (r *Redactor) Redact(text string) string {
+	if r == nil || text == "" {
+		return text
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	text = r.redactSecretManifests(text)
+	for _, rule := range r.rules {
+		text = r.applyRule(rule, text)
+	}
+	if r.entropy {
+		text = r.redactHighEntropy(text)
+	}
+	return text
+}
+
+// Restore replaces placeholders with the secrets they stand for
+func (r *Redactor) Restore(text string) string {
+	if r == nil {
+		return text
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.secrets) == 0 {
+		return text
+	}
+	return placeholderPattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		if secret, ok := r.secrets[placeholder]; ok {
+			return secret
+		}
+		return placeholder
+	})
+}
+
+// Findings returns the secrets replaced so far, in the order they were
+// first seen
+func (r *Redactor) Findings() []Finding {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Finding{}, r.findings...)
+}
+
+// placeholder returns the placeholder for a secret, creating it when the
+// secret has not been seen before
+func (r *Redactor) placeholder(kind, secret string) string {
+	if placeholder, ok := r.placeholders[secret]; ok {
+		return placeholder
+	}
+
+	r.counts[kind]++
+	name := strings.ToUpper(strings.NewReplacer("-", "_", " ", "_").Replace(kind))
+	placeholder := fmt.Sprintf("%s%s_%d]", PlaceholderPrefix, name, r.counts[kind])
+	r.placeholders[secret] = placeholder
+	r.secrets[placeholder] = secret
+	r.findings = append(r.findings, Finding{Kind: kind, Placeholder: placeholder})
+	return placeholder
+}
+
+// applyRule replaces every match of a rule
+func (r *Redactor) applyRule(rule Rule, text string) string {
+	matches := rule.Pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		if rule.Group > 0 {
+			if len(m) <= 2*rule.Group+1 || m[2*rule.Group] < 0 {
+				continue
+			}
+			start, end = m[2*rule.Group], m[2*rule.Group+1]
+		}
+		secret := text[start:end]
+		if start < last || skipValue(secret) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(r.placeholder(rule.Kind, secret))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// skipValue reports whether a matched value is not a secret, such as an
+// existing placeholder, a template reference or a boolean
+func skipValue(value string) bool {
+	if len(value) < 4 || strings.Contains(value, PlaceholderPrefix) || strings.Contains(value, "REDACTED") {
+		return true
+	}
+	for _, prefix := range []string{"${", "$(", "{{", "<", "|", ">", "*"} {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	switch strings.ToLower(value) {
+	case "true", "false", "null", "none", "changeme", "required", "optional":
+		return true
+	}
+	return false
+}
+
+var secretKindPattern = regexp.MustCompile(`(?m)^kind:\s*["']?Secret["']?\s*$`)
+var secretDataPattern = regexp.MustCompile(`^(\s*)(data|stringData):\s*$`)
+var secretEntryPattern = regexp.MustCompile(`^(\s*)([^:#\s][^:]*):\s*(.*)$`)
+
+// redactSecretManifests replaces the values under data and stringData in
+// Kubernetes Secret manifests
+func (r *Redactor) redactSecretManifests(text string) string {
+	if !secretKindPattern.MatchString(text) {
+		return text
+	}
+
+	docs := splitYAMLDocuments(text)
+	for i, doc := range docs {
+		if !secretKindPattern.MatchString(doc) {
+			continue
+		}
+
+		lines := strings.Split(doc, "\n")
+		blockIndent, entryIndent := -1, -1
+		for j, line := range lines {
+			if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+				continue
+			}
+			indent := len(line) - len(strings.TrimLeft(line, " "))
+
+			if blockIndent >= 0 && indent <= blockIndent {
+				blockIndent, entryIndent = -1, -1
+			}
+			if blockIndent < 0 {
+				if m := secretDataPattern.FindStringSubmatch(line); m != nil {
+					blockIndent = len(m[1])
+				}
+				continue
+			}
+
+			if entryIndent < 0 {
+				entryIndent = indent
+			}
+			if indent > entryIndent {
+				// Continuation of a multi-line value
+				lines[j] = line[:indent] + r.placeholder("k8s-secret-data", strings.TrimSpace(line))
+				continue
+			}
+			if m := secretEntryPattern.FindStringSubmatch(line); m != nil && !skipValue(m[3]) {
+				lines[j] = fmt.Sprintf("%s%s: %s", m[1], m[2], r.placeholder("k8s-secret-data", m[3]))
+			}
+		}
+		docs[i] = strings.Join(lines, "\n")
+	}
+	return strings.Join(docs, "")
+}
+
+// splitYAMLDocuments splits text into YAML documents, keeping the ---
+// separators so joining the parts gives back the original text
+func splitYAMLDocuments(text string) []string {
+	docs := []string{}
+	start := 0
+	for i := 0; i < len(text); {
+		end := strings.IndexByte(text[i:], '\n')
+		if end < 0 {
+			break
+		}
+		line := text[i : i+end]
+		if i > start && strings.TrimRight(line, " \r") == "---" {
+			docs = append(docs, text[start:i])
+			start = i
+		}
+		i += end + 1
+	}
+	return append(docs, text[start:])
+}
+
+var tokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_\-]{24,}`)
+
+// redactHighEntropy replaces long random looking tokens
+func (r *Redactor) redactHighEntropy(text string) string {
+	return tokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+		if !looksRandom(token) {
+			return token
+		}
+		return r.placeholder("high-entropy", token)
+	})
+}
+
+// looksRandom reports whether a token looks like a generated secret
+func looksRandom(token string) bool {
+	if len(token) < minEntropyLength || strings.Contains(token, "REDACTED") || strings.Count(token, "/") > 2 {
+		return false
+	}
+
+	var upper, lower, digit bool
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit && ShannonEntropy(token) >= entropyThreshold
+}
+
+// ShannonEntropy returns the entropy of s in bits per character
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	total := 0
+	for _, c := range s {
+		counts[c]++
+		total++
+	}
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Stream restores placeholders in text that arrives in chunks. Text that
+// may be the start of a placeholder is held back until it is complete.
+type Stream struct {
+	redactor *Redactor
+	emit     func(string)
+	pending  string
+}
+
+// NewStream returns a Stream that passes restored text to emit
+func (r *Redactor) NewStream(emit func(string)) *Stream {
+	return &Stream{redactor: r, emit: emit}
+}
+
+// Write restores and emits a chunk of text
+func (s *Stream) Write(chunk string) {
+	s.pending += chunk
+
+	held := ""
+	if i := strings.LastIndex(s.pending, "["); i >= 0 && !strings.Contains(s.pending[i:], "]") {
+		rest := s.pending[i:]
+		if len(rest) < maxPlaceholderLength && strings.HasPrefix(rest, PlaceholderPrefix) || strings.HasPrefix(PlaceholderPrefix, rest) {
+			held = rest
+			s.pending = s.pending[:i]
+		}
+	}
+
+	if s.pending != "" {
+		s.emit(s.redactor.Restore(s.pending))
+	}
+	s.pending = held
+}
+
+// Flush emits any text that is still held back
+func (s *Stream) Flush() {
+	if s.pending != "" {
+		s.emit(s.redactor.Restore(s.pending))
+		s.pending = ""
+	}
+}
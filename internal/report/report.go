@@ -0,0 +1,289 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+)
+
+// Formats that findings can be written in
+var Formats = []string{"json", "sarif", "junit"}
+
+// Finding is a problem found in a file, by a rule or by the model
+type Finding struct {
+	Rule       string         `json:"rule"`
+	Severity   rules.Severity `json:"-"`
+	Category   string         `json:"category"`
+	File       string         `json:"file"`
+	Line       int            `json:"line,omitempty"`
+	Message    string         `json:"message"`
+	Suggestion string         `json:"suggestion,omitempty"`
+	// Source is "rule" for rule findings and "ai" for the model's
+	Source string `json:"source"`
+}
+
+// MarshalJSON writes the severity as low, medium or high
+func (f Finding) MarshalJSON() ([]byte, error) {
+	type finding Finding
+	return json.Marshal(struct {
+		Severity string `json:"severity"`
+		finding
+	}{f.Severity.String(), finding(f)})
+}
+
+// Sort orders findings by file and line
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].File != findings[j].File {
+			return findings[i].File < findings[j].File
+		}
+		return findings[i].Line < findings[j].Line
+	})
+}
+
+// AtLeast returns the findings with at least the given severity
+func AtLeast(findings []Finding, severity rules.Severity) []Finding {
+	matched := []Finding{}
+	for _, f := range findings {
+		if f.Severity >= severity {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// Write writes findings in a format. files lists every file that was
+// checked, so files without findings show up as passed in JUnit.
+func Write(w io.Writer, format string, findings []Finding, files []string, version string) error {
+	switch format {
+	case "json":
+		return writeJSON(w, findings)
+	case "sarif":
+		return writeSARIF(w, findings, version)
+	case "junit":
+		return writeJUnit(w, findings, files)
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// writeJSON writes findings as a JSON array
+func writeJSON(w io.Writer, findings []Finding) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return encoder.Encode(findings)
+}
+
+// SARIF 2.1.0 types, only the parts that are written
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Version        string      `json:"version,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string          `json:"id"`
+	ShortDescription *sarifMessage   `json:"shortDescription,omitempty"`
+	Help             *sarifMessage   `json:"help,omitempty"`
+	Properties       sarifProperties `json:"properties"`
+}
+
+type sarifProperties struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifLevels maps severities to SARIF result levels
+var sarifLevels = map[rules.Severity]string{
+	rules.Low:    "note",
+	rules.Medium: "warning",
+	rules.High:   "error",
+}
+
+// writeSARIF writes findings as a SARIF 2.1.0 log for code scanning
+func writeSARIF(w io.Writer, findings []Finding, version string) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "devopscli",
+			InformationURI: "https://github.com/ruanbekker/devops-ai-cli",
+			Version:        version,
+			Rules:          []sarifRule{},
+		}},
+		Results: []sarifResult{},
+	}
+
+	seen := map[string]bool{}
+	for _, f := range findings {
+		if !seen[f.Rule] {
+			seen[f.Rule] = true
+			rule := sarifRule{ID: f.Rule, Properties: sarifProperties{Tags: []string{f.Category}}}
+			if r, ok := rules.Find(f.Rule); ok {
+				rule.ShortDescription = &sarifMessage{Text: r.Description}
+				rule.Help = &sarifMessage{Text: r.Suggestion}
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		message := f.Message
+		if f.Suggestion != "" {
+			message += " " + f.Suggestion
+		}
+		location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(f.File)},
+		}}
+		if f.Line > 0 {
+			location.PhysicalLocation.Region = &sarifRegion{StartLine: f.Line}
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.Rule,
+			Level:     sarifLevels[f.Severity],
+			Message:   sarifMessage{Text: message},
+			Locations: []sarifLocation{location},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// JUnit XML types
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes a test suite per file with a failed test case per
+// finding, or a passed one when the file has no findings
+func writeJUnit(w io.Writer, findings []Finding, files []string) error {
+	byFile := map[string][]Finding{}
+	for _, f := range findings {
+		byFile[f.File] = append(byFile[f.File], f)
+	}
+	for file := range byFile {
+		if !contains(files, file) {
+			files = append(files, file)
+		}
+	}
+
+	suites := junitSuites{}
+	for _, file := range files {
+		suite := junitSuite{Name: file}
+		for _, f := range byFile[file] {
+			text := fmt.Sprintf("%s:%d [%s] %s", f.File, f.Line, f.Severity, f.Message)
+			if f.Suggestion != "" {
+				text += "\n" + f.Suggestion
+			}
+			suite.Cases = append(suite.Cases, junitCase{
+				Name:      fmt.Sprintf("%s line %d", f.Rule, f.Line),
+				ClassName: file,
+				Failure:   &junitFailure{Message: f.Message, Type: f.Severity.String(), Text: text},
+			})
+		}
+		if len(suite.Cases) == 0 {
+			suite.Cases = []junitCase{{Name: "no findings", ClassName: file}}
+		}
+		suite.Tests = len(suite.Cases)
+		suite.Failures = len(byFile[file])
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// contains reports whether list contains s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
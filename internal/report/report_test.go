@@ -0,0 +1,87 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/rules"
+)
+
+var testFindings = []Finding{
+	{Rule: "image-tag", Severity: rules.Medium, Category: "reliability", File: "k8s/web.yaml", Line: 17, Message: "uses the latest tag", Suggestion: "Pin the image.", Source: "rule"},
+	{Rule: "ai-security", Severity: rules.High, Category: "security", File: "k8s/web.yaml", Line: 3, Message: "secret in plain text", Source: "ai"},
+}
+
+// TestWriteJSON checks the severity is written by name
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "json", testFindings, nil, "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded []map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(decoded) != 2 || decoded[0]["severity"] != "medium" || decoded[0]["line"] != float64(17) || decoded[1]["source"] != "ai" {
+		t.Errorf("unexpected JSON %s", buf.String())
+	}
+}
+
+// TestWriteSARIF checks results, levels and rule metadata
+func TestWriteSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "sarif", testFindings, nil, "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("invalid SARIF: %v", err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 {
+		t.Fatalf("unexpected SARIF %s", buf.String())
+	}
+	result := log.Runs[0].Results[0]
+	if result.Level != "warning" || result.Locations[0].PhysicalLocation.Region.StartLine != 17 || result.Locations[0].PhysicalLocation.ArtifactLocation.URI != "k8s/web.yaml" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if log.Runs[0].Results[1].Level != "error" {
+		t.Errorf("expected high findings as errors, got %q", log.Runs[0].Results[1].Level)
+	}
+	if rule := log.Runs[0].Tool.Driver.Rules[0]; rule.ShortDescription == nil || rule.Properties.Tags[0] != "reliability" {
+		t.Errorf("unexpected rule %+v", rule)
+	}
+}
+
+// TestWriteJUnit checks failed cases per finding and passed files
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, "junit", testFindings, []string{"k8s/web.yaml", "k8s/db.yaml"}, "1.0.0"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var suites junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("invalid XML: %v", err)
+	}
+	if suites.Tests != 3 || suites.Failures != 2 || len(suites.Suites) != 2 {
+		t.Errorf("unexpected suites %+v", suites)
+	}
+	if suites.Suites[1].Cases[0].Failure != nil {
+		t.Errorf("expected a passed case for a file without findings")
+	}
+	if !strings.HasPrefix(buf.String(), "<?xml") {
+		t.Errorf("expected an XML header")
+	}
+}
+
+// TestAtLeast checks findings are filtered by severity
+func TestAtLeast(t *testing.T) {
+	if got := AtLeast(testFindings, rules.High); len(got) != 1 || got[0].Rule != "ai-security" {
+		t.Errorf("unexpected findings %+v", got)
+	}
+	if got := AtLeast(testFindings, rules.Low); len(got) != 2 {
+		t.Errorf("expected all findings, got %+v", got)
+	}
+}
@@ -0,0 +1,364 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+// DockerfileRules are the Dockerfile best-practice rules
+var DockerfileRules = []Rule{
+	{
+		ID:          "base-image-tag",
+		Severity:    Medium,
+		Category:    Reliability,
+		Description: "Base images use a fixed tag or digest instead of latest",
+		Suggestion:  "Pin the base image to a version tag or a digest.",
+		checkStages: checkBaseImageTag,
+	},
+	{
+		ID:          "apt-cleanup",
+		Severity:    Low,
+		Category:    Performance,
+		Description: "apt-get install skips recommended packages and removes the package lists",
+		Suggestion:  "Use apt-get install --no-install-recommends and rm -rf /var/lib/apt/lists/* in the same RUN instruction.",
+		checkStages: checkAptCleanup,
+	},
+	{
+		ID:          "root-user",
+		Severity:    High,
+		Category:    Security,
+		Description: "The final stage switches to a non-root user",
+		Suggestion:  "Create a user and switch to it with USER before the CMD or ENTRYPOINT.",
+		checkStages: checkRootUser,
+	},
+	{
+		ID:          "add-instead-of-copy",
+		Severity:    Low,
+		Category:    Security,
+		Description: "Local files are copied with COPY instead of ADD",
+		Suggestion:  "Use COPY for local files and keep ADD for remote URLs and archives that should be extracted.",
+		checkStages: checkAddInsteadOfCopy,
+	},
+	{
+		ID:          "healthcheck",
+		Severity:    Low,
+		Category:    Reliability,
+		Description: "The final stage has a HEALTHCHECK",
+		Suggestion:  "Add a HEALTHCHECK so the runtime can tell when the container stopped working.",
+		checkStages: checkHealthcheck,
+	},
+	{
+		ID:          "layer-order",
+		Severity:    Medium,
+		Category:    Performance,
+		Description: "Dependencies are installed before the source is copied",
+		Suggestion:  "Copy only the dependency manifests (e.g. package.json, go.mod, requirements.txt) and install the dependencies before copying the rest of the source.",
+		checkStages: checkLayerOrder,
+	},
+}
+
+// stage is a build stage of a Dockerfile, from its FROM instruction up to
+// the next one
+type stage struct {
+	// name is the name given with AS, if any
+	name     string
+	image    string
+	resource string
+	from     *parser.Node
+	nodes    []*parser.Node
+}
+
+var (
+	aptInstallPattern = regexp.MustCompile(`\bapt(-get)?\s+(-\S+\s+)*install\b`)
+	dependencyPattern = regexp.MustCompile(`\b(npm (ci|install)|yarn install|pnpm install|pip3? install\s.*-r\s|go mod download|bundle install|composer install|poetry install|cargo fetch|mvn\s.*dependency:)`)
+	archivePattern    = regexp.MustCompile(`\.(tar|tar\.gz|tgz|tar\.bz2|tbz2?|tar\.xz|txz)$`)
+)
+
+// CheckDockerfile runs the rules on the stages of a Dockerfile and returns
+// the findings ordered by line
+func CheckDockerfile(content string, rules []Rule) ([]Finding, error) {
+	stages, err := parseStages(content)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := []Finding{}
+	for _, rule := range rules {
+		if rule.checkStages != nil {
+			findings = append(findings, rule.checkStages(rule, stages)...)
+		}
+	}
+	sortByLine(findings)
+	return findings, nil
+}
+
+// parseStages reads the build stages of a Dockerfile. ARG defaults declared
+// before the first FROM are expanded in the base image names.
+func parseStages(content string) ([]stage, error) {
+	result, err := parser.Parse(strings.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	args := map[string]string{}
+	stages := []stage{}
+	for _, node := range result.AST.Children {
+		switch instruction(node) {
+		case "arg":
+			if len(stages) == 0 {
+				for _, arg := range arguments(node) {
+					if name, value, ok := strings.Cut(arg, "="); ok {
+						args[name] = strings.Trim(value, `"'`)
+					}
+				}
+			} else {
+				stages[len(stages)-1].nodes = append(stages[len(stages)-1].nodes, node)
+			}
+		case "from":
+			words := arguments(node)
+			if len(words) == 0 {
+				continue
+			}
+			s := stage{image: expandArgs(words[0], args), from: node}
+			if len(words) == 3 && strings.EqualFold(words[1], "as") {
+				s.name = words[2]
+			}
+			s.resource = fmt.Sprintf("stage %d", len(stages)+1)
+			if s.name != "" {
+				s.resource = "stage " + s.name
+			}
+			stages = append(stages, s)
+		default:
+			if len(stages) > 0 {
+				stages[len(stages)-1].nodes = append(stages[len(stages)-1].nodes, node)
+			}
+		}
+	}
+	return stages, nil
+}
+
+// instruction returns the lower case instruction of a node
+func instruction(node *parser.Node) string {
+	return strings.ToLower(node.Value)
+}
+
+// arguments returns the arguments of an instruction
+func arguments(node *parser.Node) []string {
+	words := []string{}
+	for next := node.Next; next != nil; next = next.Next {
+		words = append(words, next.Value)
+	}
+	return words
+}
+
+// script returns the command of a RUN instruction with its heredocs
+func script(node *parser.Node) string {
+	parts := arguments(node)
+	for _, heredoc := range node.Heredocs {
+		parts = append(parts, heredoc.Content)
+	}
+	return strings.Join(parts, " ")
+}
+
+// hasFlag reports whether an instruction has a flag starting with prefix
+func hasFlag(node *parser.Node, prefix string) bool {
+	for _, flag := range node.Flags {
+		if strings.HasPrefix(flag, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// expandArgs replaces $NAME and ${NAME} with ARG defaults, leaving
+// variables without a default in place
+func expandArgs(s string, args map[string]string) string {
+	return os.Expand(s, func(name string) string {
+		if value, ok := args[name]; ok {
+			return value
+		}
+		return "${" + name + "}"
+	})
+}
+
+// stageFinding builds a finding for a rule in a stage
+func (r Rule) stageFinding(s stage, line int, format string, args ...interface{}) Finding {
+	return Finding{
+		Rule:       r.ID,
+		Severity:   r.Severity,
+		Category:   r.Category,
+		Resource:   s.resource,
+		Line:       line,
+		Message:    fmt.Sprintf(format, args...),
+		Suggestion: r.Suggestion,
+	}
+}
+
+// parentStage returns the index of the earlier stage that stage i is
+// built from
+func parentStage(stages []stage, i int) (int, bool) {
+	for j := i - 1; j >= 0; j-- {
+		if stages[j].name != "" && strings.EqualFold(stages[j].name, stages[i].image) {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// lastInstruction returns the last instruction of a kind in a stage or the
+// earlier stages it is built from
+func lastInstruction(stages []stage, i int, kind string) *parser.Node {
+	for j := len(stages[i].nodes) - 1; j >= 0; j-- {
+		if instruction(stages[i].nodes[j]) == kind {
+			return stages[i].nodes[j]
+		}
+	}
+	if parent, ok := parentStage(stages, i); ok {
+		return lastInstruction(stages, parent, kind)
+	}
+	return nil
+}
+
+// checkBaseImageTag reports base images without a tag or with latest
+func checkBaseImageTag(r Rule, stages []stage) []Finding {
+	findings := []Finding{}
+	for i, s := range stages {
+		_, isStage := parentStage(stages, i)
+		if isStage || strings.EqualFold(s.image, "scratch") || strings.Contains(s.image, "$") || strings.Contains(s.image, "@") {
+			continue
+		}
+		name := s.image[strings.LastIndex(s.image, "/")+1:]
+		_, tag, tagged := strings.Cut(name, ":")
+		if !tagged {
+			findings = append(findings, r.stageFinding(s, s.from.StartLine, "base image %s has no tag", s.image))
+		} else if tag == "latest" {
+			findings = append(findings, r.stageFinding(s, s.from.StartLine, "base image %s uses the latest tag", s.image))
+		}
+	}
+	return findings
+}
+
+// checkAptCleanup reports apt-get install without --no-install-recommends
+// or without removing the package lists. Package lists on a cache mount are
+// not part of the image.
+func checkAptCleanup(r Rule, stages []stage) []Finding {
+	findings := []Finding{}
+	for _, s := range stages {
+		for _, node := range s.nodes {
+			command := script(node)
+			if instruction(node) != "run" || !aptInstallPattern.MatchString(command) {
+				continue
+			}
+			missing := []string{}
+			if !strings.Contains(command, "--no-install-recommends") {
+				missing = append(missing, "--no-install-recommends")
+			}
+			if !strings.Contains(command, "/var/lib/apt/lists") && !hasFlag(node, "--mount=") {
+				missing = append(missing, "removing /var/lib/apt/lists")
+			}
+			if len(missing) > 0 {
+				findings = append(findings, r.stageFinding(s, node.StartLine, "apt-get install without %s", strings.Join(missing, " and ")))
+			}
+		}
+	}
+	return findings
+}
+
+// checkRootUser reports a final stage that runs as root. Images such as
+// distroless :nonroot set a user themselves.
+func checkRootUser(r Rule, stages []stage) []Finding {
+	if len(stages) == 0 {
+		return nil
+	}
+	last := len(stages) - 1
+	final := stages[last]
+
+	user := lastInstruction(stages, last, "user")
+	if user == nil {
+		if strings.Contains(final.image, "nonroot") {
+			return nil
+		}
+		return []Finding{r.stageFinding(final, final.from.StartLine, "no USER instruction, the container runs as root")}
+	}
+	words := arguments(user)
+	if len(words) == 0 {
+		return nil
+	}
+	if name, _, _ := strings.Cut(words[0], ":"); name == "root" || name == "0" {
+		return []Finding{r.stageFinding(final, user.StartLine, "the container runs as %s", words[0])}
+	}
+	return nil
+}
+
+// checkAddInsteadOfCopy reports ADD of local files that are not archives
+func checkAddInsteadOfCopy(r Rule, stages []stage) []Finding {
+	findings := []Finding{}
+	for _, s := range stages {
+		for _, node := range s.nodes {
+			words := arguments(node)
+			if instruction(node) != "add" || len(words) < 2 {
+				continue
+			}
+			for _, source := range words[:len(words)-1] {
+				remote := strings.Contains(source, "://") || strings.HasPrefix(source, "git@")
+				if !remote && !archivePattern.MatchString(source) && !strings.HasPrefix(source, "<<") {
+					findings = append(findings, r.stageFinding(s, node.StartLine, "ADD %s copies a local file, use COPY", source))
+					break
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// checkHealthcheck reports a final stage without a HEALTHCHECK
+func checkHealthcheck(r Rule, stages []stage) []Finding {
+	if len(stages) == 0 {
+		return nil
+	}
+	last := len(stages) - 1
+	if lastInstruction(stages, last, "healthcheck") != nil {
+		return nil
+	}
+	final := stages[last]
+	return []Finding{r.stageFinding(final, final.from.StartLine, "no HEALTHCHECK instruction")}
+}
+
+// checkLayerOrder reports copying the whole build context before installing
+// dependencies, which reinstalls them whenever any file changes
+func checkLayerOrder(r Rule, stages []stage) []Finding {
+	findings := []Finding{}
+	for _, s := range stages {
+		var copied *parser.Node
+		for _, node := range s.nodes {
+			switch instruction(node) {
+			case "copy", "add":
+				words := arguments(node)
+				if copied != nil || hasFlag(node, "--from") || len(words) < 2 {
+					continue
+				}
+				for _, source := range words[:len(words)-1] {
+					if source == "." || source == "./" {
+						copied = node
+					}
+				}
+			case "run":
+				if copied != nil && dependencyPattern.MatchString(script(node)) {
+					findings = append(findings, r.stageFinding(s, copied.StartLine, "%s comes before the dependency install on line %d, every source change reinstalls the dependencies", copied.Original, node.StartLine))
+					copied = nil
+				}
+			}
+		}
+	}
+	return findings
+}
+
+// Stages returns the number of build stages in a Dockerfile
+func Stages(content string) (int, error) {
+	stages, err := parseStages(content)
+	return len(stages), err
+}
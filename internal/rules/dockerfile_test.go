@@ -0,0 +1,131 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+const exampleDockerfile = `FROM node
+WORKDIR /app
+COPY . .
+RUN apt-get update && apt-get install -y curl
+RUN npm ci
+ADD config.json /app/config.json
+ADD https://example.com/tool.tar.gz /tmp/
+CMD ["node", "server.js"]
+`
+
+// TestCheckDockerfileExample checks the findings and lines for a single
+// stage Dockerfile with the common problems
+func TestCheckDockerfileExample(t *testing.T) {
+	findings, err := CheckDockerfile(exampleDockerfile, DockerfileRules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{
+		"base-image-tag":      1,
+		"root-user":           1,
+		"healthcheck":         1,
+		"layer-order":         3,
+		"apt-cleanup":         4,
+		"add-instead-of-copy": 6,
+	}
+	if len(findings) != len(want) {
+		t.Errorf("expected %d findings, got %+v", len(want), findings)
+	}
+	for _, f := range findings {
+		if line, ok := want[f.Rule]; !ok || f.Line != line {
+			t.Errorf("unexpected finding %+v", f)
+		}
+		if f.Resource != "stage 1" {
+			t.Errorf("unexpected resource %q", f.Resource)
+		}
+	}
+}
+
+// TestCheckDockerfileMultiStage checks a hardened multi-stage build has no
+// findings
+func TestCheckDockerfileMultiStage(t *testing.T) {
+	content := `ARG GO_VERSION=1.22
+FROM golang:${GO_VERSION} AS build
+WORKDIR /src
+COPY go.mod go.sum ./
+RUN go mod download
+COPY . .
+RUN go build -o /app .
+
+FROM debian:12-slim AS base
+RUN apt-get update && apt-get install -y --no-install-recommends ca-certificates \
+    && rm -rf /var/lib/apt/lists/*
+USER 1000
+
+FROM base
+COPY --from=build /app /app
+HEALTHCHECK CMD ["/app", "health"]
+ENTRYPOINT ["/app"]
+`
+	findings, err := CheckDockerfile(content, DockerfileRules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected no findings, got %+v", findings)
+	}
+}
+
+// TestCheckDockerfileImages checks base image pinning
+func TestCheckDockerfileImages(t *testing.T) {
+	tests := []struct {
+		from string
+		want string
+	}{
+		{"nginx:1.27", ""},
+		{"nginx:latest", "uses the latest tag"},
+		{"registry:5000/team/app", "has no tag"},
+		{"registry:5000/team/app:2.1", ""},
+		{"alpine@sha256:0123", ""},
+		{"scratch", ""},
+		{"$IMAGE", ""},
+	}
+
+	rule, _ := Find("base-image-tag")
+	for _, tt := range tests {
+		findings, err := CheckDockerfile("FROM "+tt.from+"\n", []Rule{rule})
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", tt.from, err)
+		}
+		if tt.want == "" {
+			if len(findings) != 0 {
+				t.Errorf("expected no findings for %s, got %+v", tt.from, findings)
+			}
+			continue
+		}
+		if len(findings) != 1 || !strings.Contains(findings[0].Message, tt.want) {
+			t.Errorf("expected %q for %s, got %+v", tt.want, tt.from, findings)
+		}
+	}
+}
+
+// TestCheckDockerfileRootUser checks USER root in the final stage is reported
+func TestCheckDockerfileRootUser(t *testing.T) {
+	content := "FROM alpine:3.20 AS app\nUSER app\nRUN apk add --no-cache curl\nUSER root:root\n"
+	rule, _ := Find("root-user")
+	findings, err := CheckDockerfile(content, []Rule{rule})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Line != 4 || findings[0].Resource != "stage app" {
+		t.Errorf("expected a finding on line 4, got %+v", findings)
+	}
+}
+
+// TestCheckSkipsDockerfileRules checks manifests only run Kubernetes rules
+func TestCheckSkipsDockerfileRules(t *testing.T) {
+	if _, err := Check(exampleDeployment, All()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, ok := Find("layer-order"); !ok {
+		t.Errorf("expected Find to return Dockerfile rules")
+	}
+}
@@ -0,0 +1,260 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Change is a fix made to a manifest
+type Change struct {
+	Rule        string
+	Resource    string
+	Line        int
+	Description string
+}
+
+// Values used by the fixes, meant as a starting point to tune
+const (
+	defaultCPURequest    = "100m"
+	defaultMemoryRequest = "128Mi"
+	defaultMemoryLimit   = "256Mi"
+)
+
+// edits collects line based changes to a manifest, so that comments,
+// ordering and formatting of everything else stay as they are
+type edits struct {
+	lines []string
+	// after holds lines to insert after a line number
+	after map[int][]string
+	// replace holds lines that replace a line number
+	replace map[int][]string
+	// appended holds lines added at the end
+	appended []string
+}
+
+// apply returns the content with the edits applied
+func (e *edits) apply() string {
+	out := []string{}
+	for i, line := range e.lines {
+		n := i + 1
+		if replacement, ok := e.replace[n]; ok {
+			out = append(out, replacement...)
+		} else {
+			out = append(out, line)
+		}
+		out = append(out, e.after[n]...)
+	}
+	if len(e.appended) > 0 {
+		if len(out) > 0 && out[len(out)-1] == "" {
+			out = out[:len(out)-1]
+		}
+		out = append(out, e.appended...)
+		out = append(out, "")
+	}
+	return strings.Join(out, "\n")
+}
+
+// Fix applies the deterministic fixes for rule findings: resource requests
+// and limits, imagePullPolicy, a non-root security context, probe templates
+// and a PodDisruptionBudget. Findings that need a decision, such as which
+// image tag to use, are left alone.
+func Fix(content string) (string, []Change, error) {
+	docs, err := parse(content)
+	if err != nil {
+		return content, nil, err
+	}
+
+	e := &edits{
+		lines:   strings.Split(content, "\n"),
+		after:   map[int][]string{},
+		replace: map[int][]string{},
+	}
+	fixes := []Change{}
+	for _, d := range docs {
+		for _, c := range containers(d.podSpec, false) {
+			fixes = append(fixes, fixContainer(e, d, c)...)
+		}
+	}
+	budgets, _ := Find("pod-disruption-budget")
+	for _, finding := range budgets.check(budgets, docs) {
+		for _, d := range docs {
+			if d.resource != finding.Resource {
+				continue
+			}
+			if f, ok := addDisruptionBudget(e, d); ok {
+				fixes = append(fixes, f)
+			}
+		}
+	}
+
+	sort.SliceStable(fixes, func(i, j int) bool {
+		return fixes[i].Line < fixes[j].Line
+	})
+	if len(fixes) == 0 {
+		return content, fixes, nil
+	}
+	return e.apply(), fixes, nil
+}
+
+// fixContainer adds the missing settings of a container after its image
+func fixContainer(e *edits, d document, c *yaml.Node) []Change {
+	imageLine := keyLine(c, "image")
+	if imageLine == 0 {
+		return nil
+	}
+	indent := strings.Repeat(" ", keyColumn(c, "image")-1)
+	name := scalar(c, "name")
+	fixes := []Change{}
+	add := func(rule, description string, lines ...string) {
+		for _, line := range lines {
+			e.after[imageLine] = append(e.after[imageLine], indent+line)
+		}
+		fixes = append(fixes, Change{Rule: rule, Resource: d.resource, Line: imageLine, Description: fmt.Sprintf("container %q: %s", name, description)})
+	}
+
+	if value(c, "imagePullPolicy") == nil && pinnedImage(scalar(c, "image")) {
+		add("image-pull-policy", "set imagePullPolicy: IfNotPresent", "imagePullPolicy: IfNotPresent")
+	}
+
+	requests := []string{"requests:", "  cpu: " + defaultCPURequest, "  memory: " + defaultMemoryRequest}
+	limits := []string{"limits:", "  memory: " + defaultMemoryLimit}
+	resources := value(c, "resources")
+	switch {
+	case resources == nil:
+		add("resource-requests", "added CPU and memory requests and a memory limit", append(append([]string{"resources:"}, indented(requests)...), indented(limits)...)...)
+	default:
+		if value(resources, "requests") == nil && addToMapping(e, c, "resources", requests) {
+			fixes = append(fixes, Change{Rule: "resource-requests", Resource: d.resource, Line: keyLine(c, "resources"), Description: fmt.Sprintf("container %q: added CPU and memory requests", name)})
+		}
+		if value(resources, "limits") == nil && addToMapping(e, c, "resources", limits) {
+			fixes = append(fixes, Change{Rule: "resource-limits", Resource: d.resource, Line: keyLine(c, "resources"), Description: fmt.Sprintf("container %q: added a memory limit", name)})
+		}
+	}
+
+	podContext := value(d.podSpec, "securityContext")
+	context := value(c, "securityContext")
+	if firstScalar("runAsNonRoot", context, podContext) == "" && firstScalar("runAsUser", context, podContext) == "" {
+		if context == nil {
+			add("run-as-root", "added a non-root security context", "securityContext:", "  runAsNonRoot: true", "  allowPrivilegeEscalation: false")
+		} else if addToMapping(e, c, "securityContext", []string{"runAsNonRoot: true"}) {
+			fixes = append(fixes, Change{Rule: "run-as-root", Resource: d.resource, Line: keyLine(c, "securityContext"), Description: fmt.Sprintf("container %q: set runAsNonRoot: true", name)})
+		}
+	}
+
+	if d.kind != "Job" && d.kind != "CronJob" {
+		if port := firstContainerPort(c); port != "" {
+			probes := []string{"# Probe templates, point them at the health endpoint of the application"}
+			if value(c, "livenessProbe") == nil {
+				probes = append(probes, "livenessProbe:", "  tcpSocket:", "    port: "+port, "  initialDelaySeconds: 10", "  periodSeconds: 10")
+			}
+			if value(c, "readinessProbe") == nil {
+				probes = append(probes, "readinessProbe:", "  tcpSocket:", "    port: "+port, "  periodSeconds: 5")
+			}
+			if len(probes) > 1 {
+				add("probes", "added liveness and readiness probe templates on port "+port, probes...)
+			}
+		}
+	}
+	return fixes
+}
+
+// addToMapping adds lines to the mapping under key, which may be an empty
+// flow mapping such as resources: {}. It returns false when the mapping
+// cannot be edited line by line.
+func addToMapping(e *edits, parent *yaml.Node, key string, lines []string) bool {
+	line := keyLine(parent, key)
+	node := value(parent, key)
+	indent := strings.Repeat(" ", keyColumn(parent, key)-1)
+
+	switch {
+	case node.Kind == yaml.MappingNode && node.Style&yaml.FlowStyle == 0 && len(node.Content) > 0:
+		childIndent := strings.Repeat(" ", node.Content[0].Column-1)
+		for _, l := range lines {
+			e.after[line] = append(e.after[line], childIndent+l)
+		}
+		return true
+	case node.Kind == yaml.ScalarNode && node.Tag == "!!null" && node.Value == "":
+		// key: without a value
+		for _, l := range indented(lines) {
+			e.after[line] = append(e.after[line], indent+l)
+		}
+		return true
+	case node.Kind == yaml.MappingNode && len(node.Content) == 0:
+		// key: {} becomes a block mapping, keeping a trailing comment
+		if _, done := e.replace[line]; !done {
+			original := e.lines[line-1]
+			first := strings.TrimRight(original[:node.Column-1], " ")
+			if rest := strings.TrimSpace(strings.TrimPrefix(original[node.Column-1:], "{}")); rest != "" {
+				first += " " + rest
+			}
+			e.replace[line] = []string{first}
+		}
+		for _, l := range indented(lines) {
+			e.replace[line] = append(e.replace[line], indent+l)
+		}
+		return true
+	}
+	return false
+}
+
+// addDisruptionBudget appends a PodDisruptionBudget selecting the pods of
+// a workload
+func addDisruptionBudget(e *edits, d document) (Change, bool) {
+	selector := path(d.root, "spec", "selector", "matchLabels")
+	if selector == nil || selector.Kind != yaml.MappingNode || len(selector.Content) == 0 {
+		return Change{}, false
+	}
+
+	name := scalar(value(d.root, "metadata"), "name")
+	e.appended = append(e.appended, "---", "apiVersion: policy/v1", "kind: PodDisruptionBudget", "metadata:", "  name: "+name)
+	if namespace := scalar(value(d.root, "metadata"), "namespace"); namespace != "" {
+		e.appended = append(e.appended, "  namespace: "+namespace)
+	}
+	e.appended = append(e.appended, "spec:", "  minAvailable: 1", "  selector:", "    matchLabels:")
+	for i := 0; i+1 < len(selector.Content); i += 2 {
+		e.appended = append(e.appended, fmt.Sprintf("      %s: %s", selector.Content[i].Value, selector.Content[i+1].Value))
+	}
+	return Change{Rule: "pod-disruption-budget", Resource: d.resource, Line: keyLine(d.root, "kind"), Description: "added a PodDisruptionBudget with minAvailable: 1"}, true
+}
+
+// keyColumn returns the column of key in a mapping node, or 1
+func keyColumn(node *yaml.Node, key string) int {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i].Column
+		}
+	}
+	return 1
+}
+
+// indented indents lines by one level
+func indented(lines []string) []string {
+	out := make([]string, len(lines))
+	for i, line := range lines {
+		out[i] = "  " + line
+	}
+	return out
+}
+
+// pinnedImage reports whether an image has a tag other than latest or a
+// digest
+func pinnedImage(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	i := strings.LastIndex(name, ":")
+	return i >= 0 && name[i+1:] != "latest"
+}
+
+// firstContainerPort returns the first containerPort of a container
+func firstContainerPort(c *yaml.Node) string {
+	ports := value(c, "ports")
+	if ports == nil || ports.Kind != yaml.SequenceNode || len(ports.Content) == 0 {
+		return ""
+	}
+	return scalar(ports.Content[0], "containerPort")
+}
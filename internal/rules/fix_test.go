@@ -0,0 +1,99 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFixExampleDeployment checks the fixes keep the rest of the manifest,
+// including comments, and leave only findings that need a decision
+func TestFixExampleDeployment(t *testing.T) {
+	content := strings.Replace(exampleDeployment, "  replicas: 1\n", "  replicas: 1 # scaled by the HPA\n", 1)
+	fixed, changes, err := Fix(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `    spec:
+      containers:
+      - name: example-container
+        image: example-image
+        securityContext:
+          runAsNonRoot: true
+          allowPrivilegeEscalation: false
+        # Probe templates, point them at the health endpoint of the application
+        livenessProbe:
+          tcpSocket:
+            port: 8080
+          initialDelaySeconds: 10
+          periodSeconds: 10
+        readinessProbe:
+          tcpSocket:
+            port: 8080
+          periodSeconds: 5
+        ports:
+        - containerPort: 8080
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+          limits:
+            memory: 256Mi
+`
+	if !strings.HasSuffix(fixed, want) || !strings.Contains(fixed, "replicas: 1 # scaled by the HPA\n") {
+		t.Errorf("unexpected fixed manifest:\n%s", fixed)
+	}
+	if len(changes) != 4 {
+		t.Errorf("expected 4 changes, got %+v", changes)
+	}
+
+	findings, err := Check(fixed, Rules)
+	if err != nil {
+		t.Fatalf("fixed manifest does not parse: %v", err)
+	}
+	if len(findings) != 1 || findings[0].Rule != "image-tag" {
+		t.Errorf("expected only the image tag finding to remain, got %+v", findings)
+	}
+}
+
+// TestFixBlockMappings checks settings are added to existing mappings and a
+// PodDisruptionBudget is appended for replicated workloads
+func TestFixBlockMappings(t *testing.T) {
+	content := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  selector:
+    matchLabels:
+      app: web
+  template:
+    spec:
+      containers:
+        - name: web
+          image: nginx:1.27
+          imagePullPolicy: Always
+          resources:
+            limits:
+              memory: 512Mi
+          securityContext:
+            readOnlyRootFilesystem: true
+`
+	fixed, _, err := Fix(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"          resources:\n            requests:\n              cpu: 100m\n              memory: 128Mi\n            limits:\n              memory: 512Mi\n",
+		"          securityContext:\n            runAsNonRoot: true\n            readOnlyRootFilesystem: true\n",
+		"---\napiVersion: policy/v1\nkind: PodDisruptionBudget\nmetadata:\n  name: web\nspec:\n  minAvailable: 1\n  selector:\n    matchLabels:\n      app: web\n",
+	} {
+		if !strings.Contains(fixed, want) {
+			t.Errorf("expected %q in:\n%s", want, fixed)
+		}
+	}
+	if strings.Count(fixed, "imagePullPolicy") != 1 {
+		t.Errorf("expected the existing imagePullPolicy to be kept:\n%s", fixed)
+	}
+}
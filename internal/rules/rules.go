@@ -0,0 +1,482 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Severity is how much a finding matters
+type Severity int
+
+const (
+	Low Severity = iota + 1
+	Medium
+	High
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Low:
+		return "low"
+	case Medium:
+		return "medium"
+	case High:
+		return "high"
+	}
+	return "unknown"
+}
+
+// ParseSeverity parses low, medium or high
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "low":
+		return Low, nil
+	case "medium":
+		return Medium, nil
+	case "high":
+		return High, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q, use low, medium or high", s)
+}
+
+// Categories rules are grouped in
+const (
+	Security    = "security"
+	Reliability = "reliability"
+	Performance = "performance"
+	Cost        = "cost"
+)
+
+// Finding is a problem found by a rule
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Category string
+	// Resource is Kind/name of the document, or the stage of a Dockerfile
+	Resource   string
+	Line       int
+	Message    string
+	Suggestion string
+}
+
+// Rule checks the documents of a manifest or the stages of a Dockerfile
+type Rule struct {
+	ID          string
+	Severity    Severity
+	Category    string
+	Description string
+	Suggestion  string
+	check       func(r Rule, docs []document) []Finding
+	// checkStages is set instead of check for Dockerfile rules
+	checkStages func(r Rule, stages []stage) []Finding
+}
+
+// Rules are the Kubernetes best-practice rules
+var Rules = []Rule{
+	{
+		ID:          "resource-requests",
+		Severity:    Medium,
+		Category:    Reliability,
+		Description: "Containers set CPU and memory requests",
+		Suggestion:  "Set resources.requests for cpu and memory so the scheduler can place the pod.",
+		check:       checkResources("requests"),
+	},
+	{
+		ID:          "resource-limits",
+		Severity:    Medium,
+		Category:    Cost,
+		Description: "Containers set a memory limit",
+		Suggestion:  "Set resources.limits, at least for memory, so one pod cannot starve the node.",
+		check:       checkResources("limits"),
+	},
+	{
+		ID:          "image-tag",
+		Severity:    Medium,
+		Category:    Reliability,
+		Description: "Images use a fixed tag or digest instead of latest",
+		Suggestion:  "Pin the image to a version tag or a digest.",
+		check:       checkImageTag,
+	},
+	{
+		ID:          "probes",
+		Severity:    Medium,
+		Category:    Reliability,
+		Description: "Long running containers have liveness and readiness probes",
+		Suggestion:  "Add a readinessProbe so traffic only reaches ready pods and a livenessProbe to restart hung containers.",
+		check:       checkProbes,
+	},
+	{
+		ID:          "run-as-root",
+		Severity:    High,
+		Category:    Security,
+		Description: "Containers do not run as root",
+		Suggestion:  "Set securityContext.runAsNonRoot: true and a non-zero runAsUser.",
+		check:       checkRunAsRoot,
+	},
+	{
+		ID:          "host-path",
+		Severity:    High,
+		Category:    Security,
+		Description: "Pods do not mount hostPath volumes",
+		Suggestion:  "Use a persistentVolumeClaim, configMap or emptyDir instead of a hostPath volume.",
+		check:       checkHostPath,
+	},
+	{
+		ID:          "pod-disruption-budget",
+		Severity:    Low,
+		Category:    Reliability,
+		Description: "Replicated workloads have a PodDisruptionBudget",
+		Suggestion:  "Add a PodDisruptionBudget selecting the pods, e.g. with minAvailable: 1.",
+		check:       checkDisruptionBudget,
+	},
+}
+
+// document is a Kubernetes resource in a manifest
+type document struct {
+	kind     string
+	resource string
+	root     *yaml.Node
+	// podSpec is nil for resources without pods
+	podSpec *yaml.Node
+}
+
+// Check runs the rules on every document in a manifest and returns the
+// findings ordered by line
+func Check(content string, rules []Rule) ([]Finding, error) {
+	docs, err := parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := []Finding{}
+	for _, rule := range rules {
+		if rule.check != nil {
+			findings = append(findings, rule.check(rule, docs)...)
+		}
+	}
+	sortByLine(findings)
+	return findings, nil
+}
+
+// All returns the Kubernetes and the Dockerfile rules
+func All() []Rule {
+	all := append([]Rule{}, Rules...)
+	return append(all, DockerfileRules...)
+}
+
+// Find returns the rule with the given ID
+func Find(id string) (Rule, bool) {
+	for _, rule := range All() {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// sortByLine orders findings by line
+func sortByLine(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Line < findings[j].Line
+	})
+}
+
+// parse reads the Kubernetes resources in a manifest
+func parse(content string) ([]document, error) {
+	docs := []document{}
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var node yaml.Node
+		if err := decoder.Decode(&node); errors.Is(err, io.EOF) {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := node.Content[0]
+		kind := scalar(root, "kind")
+		if kind == "" {
+			continue
+		}
+
+		resource := kind
+		if name := scalar(value(root, "metadata"), "name"); name != "" {
+			resource += "/" + name
+		}
+		docs = append(docs, document{kind: kind, resource: resource, root: root, podSpec: podSpec(kind, root)})
+	}
+}
+
+// podSpec returns the pod spec of a workload
+func podSpec(kind string, root *yaml.Node) *yaml.Node {
+	switch kind {
+	case "Pod":
+		return value(root, "spec")
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		return path(root, "spec", "template", "spec")
+	case "CronJob":
+		return path(root, "spec", "jobTemplate", "spec", "template", "spec")
+	}
+	return nil
+}
+
+// containers returns the containers of a pod spec, with init containers
+// when init is set
+func containers(spec *yaml.Node, init bool) []*yaml.Node {
+	list := []*yaml.Node{}
+	keys := []string{"containers"}
+	if init {
+		keys = append(keys, "initContainers")
+	}
+	for _, key := range keys {
+		if seq := value(spec, key); seq != nil && seq.Kind == yaml.SequenceNode {
+			list = append(list, seq.Content...)
+		}
+	}
+	return list
+}
+
+// finding builds a finding for a rule
+func (r Rule) finding(d document, line int, format string, args ...interface{}) Finding {
+	return Finding{
+		Rule:       r.ID,
+		Severity:   r.Severity,
+		Category:   r.Category,
+		Resource:   d.resource,
+		Line:       line,
+		Message:    fmt.Sprintf(format, args...),
+		Suggestion: r.Suggestion,
+	}
+}
+
+// checkResources reports containers without resource requests or limits
+func checkResources(field string) func(r Rule, docs []document) []Finding {
+	return func(r Rule, docs []document) []Finding {
+		findings := []Finding{}
+		for _, d := range docs {
+			for _, c := range containers(d.podSpec, true) {
+				resources := value(c, "resources")
+				if set := value(resources, field); set != nil && len(set.Content) > 0 {
+					continue
+				}
+				line := keyLine(c, "resources")
+				if line == 0 {
+					line = c.Line
+				}
+				findings = append(findings, r.finding(d, line, "container %q has no resource %s", scalar(c, "name"), field))
+			}
+		}
+		return findings
+	}
+}
+
+// checkImageTag reports images without a tag or with the latest tag
+func checkImageTag(r Rule, docs []document) []Finding {
+	findings := []Finding{}
+	for _, d := range docs {
+		for _, c := range containers(d.podSpec, true) {
+			image := scalar(c, "image")
+			if image == "" || strings.Contains(image, "@") {
+				continue
+			}
+			name := image[strings.LastIndex(image, "/")+1:]
+			tag := ""
+			if i := strings.LastIndex(name, ":"); i >= 0 {
+				tag = name[i+1:]
+			}
+			switch tag {
+			case "":
+				findings = append(findings, r.finding(d, keyLine(c, "image"), "container %q uses image %q without a tag, which means latest", scalar(c, "name"), image))
+			case "latest":
+				findings = append(findings, r.finding(d, keyLine(c, "image"), "container %q uses the latest tag of %q", scalar(c, "name"), image))
+			}
+		}
+	}
+	return findings
+}
+
+// checkProbes reports long running containers without probes
+func checkProbes(r Rule, docs []document) []Finding {
+	findings := []Finding{}
+	for _, d := range docs {
+		if d.kind == "Job" || d.kind == "CronJob" {
+			continue
+		}
+		for _, c := range containers(d.podSpec, false) {
+			missing := []string{}
+			for _, probe := range []string{"livenessProbe", "readinessProbe"} {
+				if value(c, probe) == nil {
+					missing = append(missing, probe)
+				}
+			}
+			if len(missing) > 0 {
+				findings = append(findings, r.finding(d, c.Line, "container %q has no %s", scalar(c, "name"), strings.Join(missing, " or ")))
+			}
+		}
+	}
+	return findings
+}
+
+// checkRunAsRoot reports containers that may run as root, taking the pod
+// security context into account
+func checkRunAsRoot(r Rule, docs []document) []Finding {
+	findings := []Finding{}
+	for _, d := range docs {
+		podContext := value(d.podSpec, "securityContext")
+		for _, c := range containers(d.podSpec, true) {
+			context := value(c, "securityContext")
+			nonRoot := firstScalar("runAsNonRoot", context, podContext)
+			user := firstScalar("runAsUser", context, podContext)
+
+			line := keyLine(c, "securityContext")
+			if line == 0 {
+				line = c.Line
+			}
+			switch {
+			case user == "0":
+				findings = append(findings, r.finding(d, line, "container %q runs as user 0 (root)", scalar(c, "name")))
+			case nonRoot != "true" && !nonZero(user):
+				findings = append(findings, r.finding(d, line, "container %q may run as root, runAsNonRoot is not set", scalar(c, "name")))
+			}
+		}
+	}
+	return findings
+}
+
+// checkHostPath reports hostPath volumes
+func checkHostPath(r Rule, docs []document) []Finding {
+	findings := []Finding{}
+	for _, d := range docs {
+		volumes := value(d.podSpec, "volumes")
+		if volumes == nil {
+			continue
+		}
+		for _, v := range volumes.Content {
+			if hostPath := value(v, "hostPath"); hostPath != nil {
+				findings = append(findings, r.finding(d, keyLine(v, "hostPath"), "volume %q mounts %s from the host", scalar(v, "name"), scalar(hostPath, "path")))
+			}
+		}
+	}
+	return findings
+}
+
+// checkDisruptionBudget reports Deployments and StatefulSets with more than
+// one replica that no PodDisruptionBudget in the manifest selects
+func checkDisruptionBudget(r Rule, docs []document) []Finding {
+	selectors := []map[string]string{}
+	for _, d := range docs {
+		if d.kind == "PodDisruptionBudget" {
+			selectors = append(selectors, stringMap(path(d.root, "spec", "selector", "matchLabels")))
+		}
+	}
+
+	findings := []Finding{}
+	for _, d := range docs {
+		if d.kind != "Deployment" && d.kind != "StatefulSet" {
+			continue
+		}
+		replicas, err := strconv.Atoi(scalar(value(d.root, "spec"), "replicas"))
+		if err != nil || replicas < 2 {
+			continue
+		}
+		labels := stringMap(path(d.root, "spec", "template", "metadata", "labels"))
+		covered := false
+		for _, selector := range selectors {
+			if len(selector) > 0 && subset(selector, labels) {
+				covered = true
+			}
+		}
+		if !covered {
+			findings = append(findings, r.finding(d, keyLine(d.root, "kind"), "%s has %d replicas but no PodDisruptionBudget", d.resource, replicas))
+		}
+	}
+	return findings
+}
+
+// value returns the value of key in a mapping node
+func value(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// path returns the value at a path of keys
+func path(node *yaml.Node, keys ...string) *yaml.Node {
+	for _, key := range keys {
+		node = value(node, key)
+	}
+	return node
+}
+
+// keyLine returns the line of key in a mapping node, or 0
+func keyLine(node *yaml.Node, key string) int {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return 0
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i].Line
+		}
+	}
+	return 0
+}
+
+// scalar returns the scalar value of key in a mapping node
+func scalar(node *yaml.Node, key string) string {
+	if v := value(node, key); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// firstScalar returns the first value of key that is set in the nodes
+func firstScalar(key string, nodes ...*yaml.Node) string {
+	for _, node := range nodes {
+		if v := scalar(node, key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// nonZero reports whether s is a number other than zero
+func nonZero(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n != 0
+}
+
+// stringMap returns the scalar values of a mapping node
+func stringMap(node *yaml.Node) map[string]string {
+	m := map[string]string{}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return m
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		m[node.Content[i].Value] = node.Content[i+1].Value
+	}
+	return m
+}
+
+// subset reports whether every entry of a is in b
+func subset(a, b map[string]string) bool {
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
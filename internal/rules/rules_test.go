@@ -0,0 +1,145 @@
+package rules
+
+import (
+	"testing"
+)
+
+const exampleDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: example-deployment
+spec:
+  replicas: 1
+  selector:
+    matchLabels:
+      app: example
+  template:
+    metadata:
+      labels:
+        app: example
+    spec:
+      containers:
+      - name: example-container
+        image: example-image
+        ports:
+        - containerPort: 8080
+        resources: {}
+`
+
+// TestCheckExampleDeployment checks the findings and lines for a deployment
+// without resources, probes or an image tag
+func TestCheckExampleDeployment(t *testing.T) {
+	findings, err := Check(exampleDeployment, Rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]int{
+		"resource-requests": 20,
+		"resource-limits":   20,
+		"image-tag":         17,
+		"probes":            16,
+		"run-as-root":       16,
+	}
+	if len(findings) != len(want) {
+		t.Errorf("expected %d findings, got %+v", len(want), findings)
+	}
+	for _, f := range findings {
+		if line, ok := want[f.Rule]; !ok || f.Line != line {
+			t.Errorf("unexpected finding %+v", f)
+		}
+		if f.Resource != "Deployment/example-deployment" {
+			t.Errorf("unexpected resource %q", f.Resource)
+		}
+	}
+}
+
+// TestCheckCompliantDeployment checks a hardened deployment has no findings
+func TestCheckCompliantDeployment(t *testing.T) {
+	content := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      securityContext:
+        runAsNonRoot: true
+      containers:
+      - name: web
+        image: nginx:1.27@sha256:abc
+        resources:
+          requests:
+            cpu: 100m
+          limits:
+            memory: 128Mi
+        livenessProbe:
+          httpGet: {path: /, port: 80}
+        readinessProbe:
+          httpGet: {path: /, port: 80}
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: web
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      app: web
+`
+	findings, err := Check(content, Rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("expected no findings, got %+v", findings)
+	}
+}
+
+// TestCheckHostPathAndBudget checks hostPath volumes, root users and
+// replicated workloads without a PodDisruptionBudget
+func TestCheckHostPathAndBudget(t *testing.T) {
+	content := `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: db
+spec:
+  replicas: 3
+  template:
+    spec:
+      containers:
+      - name: db
+        image: postgres:16
+        securityContext:
+          runAsUser: 0
+      volumes:
+      - name: data
+        hostPath:
+          path: /var/lib/db
+`
+	findings, err := Check(content, []Rule{mustFind(t, "host-path"), mustFind(t, "pod-disruption-budget"), mustFind(t, "run-as-root")})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := map[string]int{}
+	for _, f := range findings {
+		lines[f.Rule] = f.Line
+	}
+	if lines["pod-disruption-budget"] != 2 || lines["run-as-root"] != 12 || lines["host-path"] != 16 {
+		t.Errorf("unexpected findings %+v", findings)
+	}
+}
+
+func mustFind(t *testing.T, id string) Rule {
+	t.Helper()
+	rule, ok := Find(id)
+	if !ok {
+		t.Fatalf("rule %s not found", id)
+	}
+	return rule
+}
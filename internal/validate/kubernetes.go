@@ -0,0 +1,418 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultSchemaLocation is where Kubernetes JSON schemas are downloaded
+// from. {version} is replaced by the Kubernetes version and {file} by the
+// schema file name, e.g. deployment-apps-v1.json.
+const DefaultSchemaLocation = "https://raw.githubusercontent.com/yannh/kubernetes-json-schema/master/{version}-standalone-strict/{file}"
+
+// DefaultKubernetesVersion is the Kubernetes version validated against when
+// none is configured
+const DefaultKubernetesVersion = "1.31.0"
+
+// masterCacheTTL is how long cached schemas for "master" are used before
+// they are downloaded again, as they change with every Kubernetes release
+const masterCacheTTL = 24 * time.Hour
+
+// ErrNoSchema is returned when there is no schema for a resource, e.g. for
+// custom resources
+var ErrNoSchema = errors.New("no schema found")
+
+// Schemas loads Kubernetes JSON schemas for one Kubernetes version. Schemas
+// are downloaded once and cached, so validation works offline afterwards.
+type Schemas struct {
+	Version  string
+	Location string
+	CacheDir string
+	Client   *http.Client
+
+	mu       sync.Mutex
+	compiled map[string]*jsonschema.Schema
+}
+
+// NewSchemas returns schemas for a Kubernetes version, read from location
+// (a URL or a local path with {version} and {file} placeholders) and cached
+// in cacheDir. Empty values use the defaults.
+func NewSchemas(version, location, cacheDir string) *Schemas {
+	if version == "" {
+		version = DefaultKubernetesVersion
+	}
+	if version != "master" && !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if location == "" {
+		location = DefaultSchemaLocation
+	}
+	if cacheDir == "" {
+		if dir, err := os.UserCacheDir(); err == nil {
+			cacheDir = filepath.Join(dir, "devopscli", "schemas")
+		}
+	}
+	return &Schemas{
+		Version:  version,
+		Location: location,
+		CacheDir: cacheDir,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+		compiled: map[string]*jsonschema.Schema{},
+	}
+}
+
+// SchemaFile returns the schema file name for a resource, e.g.
+// deployment-apps-v1.json for apps/v1 Deployment
+func SchemaFile(apiVersion, kind string) string {
+	group, version := "", apiVersion
+	if i := strings.Index(apiVersion, "/"); i >= 0 {
+		group, version = apiVersion[:i], apiVersion[i+1:]
+	}
+	name := strings.ToLower(kind)
+	if group != "" {
+		name += "-" + strings.ToLower(strings.Split(group, ".")[0])
+	}
+	return name + "-" + strings.ToLower(version) + ".json"
+}
+
+// schema returns the compiled schema for a resource
+func (s *Schemas) schema(apiVersion, kind string) (*jsonschema.Schema, error) {
+	file := SchemaFile(apiVersion, kind)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if schema, ok := s.compiled[file]; ok {
+		return schema, nil
+	}
+
+	data, err := s.load(file)
+	if err != nil {
+		return nil, err
+	}
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(file, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("reading schema %s: %w", file, err)
+	}
+	schema, err := compiler.Compile(file)
+	if err != nil {
+		return nil, fmt.Errorf("compiling schema %s: %w", file, err)
+	}
+	s.compiled[file] = schema
+	return schema, nil
+}
+
+// load reads a schema file from the cache, a local location or by
+// downloading it into the cache
+func (s *Schemas) load(file string) ([]byte, error) {
+	location := strings.NewReplacer("{version}", s.Version, "{file}", file).Replace(s.Location)
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		data, err := os.ReadFile(location)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoSchema
+		}
+		return data, err
+	}
+
+	// Schemas for a released version never change. Those for master are
+	// refreshed after masterCacheTTL, keeping the stale copy for offline use.
+	cached := ""
+	var stale []byte
+	if s.CacheDir != "" {
+		cached = filepath.Join(s.CacheDir, s.Version, file)
+		if data, err := os.ReadFile(cached); err == nil {
+			info, statErr := os.Stat(cached)
+			if s.Version != "master" || statErr == nil && time.Since(info.ModTime()) < masterCacheTTL {
+				return data, nil
+			}
+			stale = data
+		}
+	}
+
+	data, err := s.download(location, file)
+	if err != nil {
+		if stale != nil && !errors.Is(err, ErrNoSchema) {
+			return stale, nil
+		}
+		return nil, err
+	}
+
+	// A failed cache write only means the schema is downloaded again
+	if cached != "" && os.MkdirAll(filepath.Dir(cached), 0755) == nil {
+		_ = os.WriteFile(cached, data, 0644)
+	}
+	return data, nil
+}
+
+// download fetches a schema file from location
+func (s *Schemas) download(location, file string) ([]byte, error) {
+	resp, err := s.Client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("downloading schema %s: %w", file, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNoSchema
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading schema %s: %s", file, resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("downloading schema %s: %w", file, err)
+	}
+	return data, nil
+}
+
+// SchemaError is a document that does not match its schema
+type SchemaError struct {
+	// Resource is Kind/name of the document
+	Resource string
+	// Path is the field, e.g. spec.template.spec.containers[0].image
+	Path    string
+	Line    int
+	Message string
+}
+
+func (e SchemaError) Error() string {
+	location := e.Resource
+	if e.Path != "" {
+		location += " " + e.Path
+	}
+	if e.Line > 0 {
+		return fmt.Sprintf("line %d: %s: %s", e.Line, location, e.Message)
+	}
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// Report is the result of validating the documents in a file
+type Report struct {
+	Documents int
+	Errors    []SchemaError
+	// Skipped lists resources without a schema, such as custom resources
+	Skipped []string
+}
+
+// Valid reports whether no schema errors were found
+func (r Report) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Validate checks every document in content against its Kubernetes schema.
+// Documents without apiVersion and kind are ignored. The error is only set
+// when the YAML does not parse or a schema cannot be loaded.
+func (s *Schemas) Validate(content string) (Report, error) {
+	report := Report{}
+	if err := YAML(content); err != nil {
+		return report, err
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); errors.Is(err, io.EOF) {
+			return report, nil
+		} else if err != nil {
+			return report, err
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		root := doc.Content[0]
+
+		apiVersion, kind := scalarValue(root, "apiVersion"), scalarValue(root, "kind")
+		if apiVersion == "" || kind == "" {
+			continue
+		}
+		report.Documents++
+		resource := kind
+		if metadata := mappingValue(root, "metadata"); metadata != nil {
+			if name := scalarValue(metadata, "name"); name != "" {
+				resource += "/" + name
+			}
+		}
+
+		schema, err := s.schema(apiVersion, kind)
+		if errors.Is(err, ErrNoSchema) {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s (%s)", resource, apiVersion))
+			continue
+		}
+		if err != nil {
+			return report, err
+		}
+
+		value, err := jsonValue(root)
+		if err != nil {
+			report.Errors = append(report.Errors, SchemaError{Resource: resource, Line: root.Line, Message: err.Error()})
+			continue
+		}
+		var validationErr *jsonschema.ValidationError
+		if err := schema.Validate(value); errors.As(err, &validationErr) {
+			for _, leaf := range leafErrors(validationErr) {
+				report.Errors = append(report.Errors, schemaError(root, resource, leaf))
+			}
+		} else if err != nil {
+			report.Errors = append(report.Errors, SchemaError{Resource: resource, Line: root.Line, Message: err.Error()})
+		}
+	}
+}
+
+// jsonValue converts a YAML node into the values encoding/json produces, as
+// expected by the schema validator
+func jsonValue(node *yaml.Node) (interface{}, error) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("not representable as JSON: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var converted interface{}
+	err = decoder.Decode(&converted)
+	return converted, err
+}
+
+// leafErrors returns the most specific validation errors. A failed oneOf or
+// anyOf, such as an int-or-string field, is reported as a single error.
+func leafErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	if strings.HasSuffix(err.KeywordLocation, "/oneOf") || strings.HasSuffix(err.KeywordLocation, "/anyOf") {
+		messages := []string{}
+		for _, cause := range err.Causes {
+			messages = append(messages, cause.Message)
+		}
+		return []*jsonschema.ValidationError{{
+			KeywordLocation:  err.KeywordLocation,
+			InstanceLocation: err.InstanceLocation,
+			Message:          strings.Join(messages, ", or "),
+		}}
+	}
+
+	leaves := []*jsonschema.ValidationError{}
+	for _, cause := range err.Causes {
+		leaves = append(leaves, leafErrors(cause)...)
+	}
+	return leaves
+}
+
+var unknownFieldPattern = regexp.MustCompile(`^additionalProperties '([^']+)'`)
+
+// schemaError converts a validation error into a SchemaError with the line
+// of the offending field
+func schemaError(root *yaml.Node, resource string, err *jsonschema.ValidationError) SchemaError {
+	pointer := err.InstanceLocation
+	message := err.Message
+	// Point unknown fields at the field itself rather than its parent
+	if m := unknownFieldPattern.FindStringSubmatch(message); m != nil && !strings.Contains(message, ",") {
+		pointer += "/" + m[1]
+		message = "unknown field"
+	}
+
+	tokens := pointerTokens(pointer)
+	return SchemaError{
+		Resource: resource,
+		Path:     fieldPath(tokens),
+		Line:     lineOf(root, tokens),
+		Message:  message,
+	}
+}
+
+// pointerTokens splits a JSON pointer into its unescaped tokens
+func pointerTokens(pointer string) []string {
+	if pointer == "" || pointer == "/" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens
+}
+
+// fieldPath formats pointer tokens as spec.containers[0].image
+func fieldPath(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		if _, err := strconv.Atoi(token); err == nil {
+			fmt.Fprintf(&b, "[%s]", token)
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+// lineOf returns the line of the node at the pointer tokens, or of the
+// deepest node that exists
+func lineOf(node *yaml.Node, tokens []string) int {
+	line := node.Line
+	for _, token := range tokens {
+		switch node.Kind {
+		case yaml.MappingNode:
+			next := (*yaml.Node)(nil)
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == token {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return line
+			}
+			node = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(token)
+			if err != nil || i >= len(node.Content) {
+				return line
+			}
+			node = node.Content[i]
+			line = node.Line
+		default:
+			return line
+		}
+	}
+	return line
+}
+
+// mappingValue returns the value of key in a mapping node
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// scalarValue returns the scalar value of key in a mapping node
+func scalarValue(node *yaml.Node, key string) string {
+	if value := mappingValue(node, key); value != nil && value.Kind == yaml.ScalarNode {
+		return value.Value
+	}
+	return ""
+}
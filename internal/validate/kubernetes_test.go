@@ -0,0 +1,155 @@
+package validate
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const deploymentSchema = `{
+  "type": "object",
+  "additionalProperties": false,
+  "properties": {
+    "apiVersion": {"type": "string"},
+    "kind": {"type": "string"},
+    "metadata": {"type": "object", "properties": {"name": {"type": "string"}}},
+    "spec": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "replicas": {"type": "integer"},
+        "maxSurge": {"oneOf": [{"type": "string"}, {"type": "integer"}]}
+      }
+    }
+  }
+}`
+
+// newSchemaServer serves the Deployment schema and counts the downloads
+func newSchemaServer(t *testing.T, downloads *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1.30.0/deployment-apps-v1.json" {
+			http.NotFound(w, r)
+			return
+		}
+		*downloads++
+		w.Write([]byte(deploymentSchema))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestSchemaFile checks schema file names for core and grouped resources
+func TestSchemaFile(t *testing.T) {
+	cases := map[string][2]string{
+		"service-v1.json":            {"v1", "Service"},
+		"deployment-apps-v1.json":    {"apps/v1", "Deployment"},
+		"ingress-networking-v1.json": {"networking.k8s.io/v1", "Ingress"},
+	}
+	for want, in := range cases {
+		if got := SchemaFile(in[0], in[1]); got != want {
+			t.Errorf("SchemaFile(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+// TestValidateKubernetes checks unknown fields and type errors are reported
+// with their lines and custom resources are skipped
+func TestValidateKubernetes(t *testing.T) {
+	downloads := 0
+	server := newSchemaServer(t, &downloads)
+	schemas := NewSchemas("1.30.0", server.URL+"/{version}/{file}", t.TempDir())
+
+	content := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: "3"
+  maxSurge: 1
+  replica: 2
+---
+apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: backup
+`
+	report, err := schemas.Validate(content)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Documents != 2 || len(report.Skipped) != 1 || !strings.HasPrefix(report.Skipped[0], "CronTab/backup") {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if len(report.Errors) != 2 {
+		t.Fatalf("expected 2 errors, got %v", report.Errors)
+	}
+
+	lines := map[string]int{}
+	for _, e := range report.Errors {
+		lines[e.Path] = e.Line
+	}
+	if lines["spec.replicas"] != 6 || lines["spec.replica"] != 8 {
+		t.Errorf("unexpected error lines %v", report.Errors)
+	}
+}
+
+// TestValidateKubernetesCache checks schemas are only downloaded once
+func TestValidateKubernetesCache(t *testing.T) {
+	downloads := 0
+	server := newSchemaServer(t, &downloads)
+	cacheDir := t.TempDir()
+	content := "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 3\n  maxSurge: 25%\n"
+
+	for i := 0; i < 2; i++ {
+		report, err := NewSchemas("v1.30.0", server.URL+"/{version}/{file}", cacheDir).Validate(content)
+		if err != nil || !report.Valid() {
+			t.Fatalf("expected a valid document, got %+v, %v", report, err)
+		}
+	}
+	if downloads != 1 {
+		t.Errorf("expected 1 download, got %d", downloads)
+	}
+}
+
+// TestValidateKubernetesMasterCache checks cached schemas for master are
+// downloaded again once they are old, and kept when the download fails
+func TestValidateKubernetesMasterCache(t *testing.T) {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		w.Write([]byte(deploymentSchema))
+	}))
+	cacheDir := t.TempDir()
+	cached := filepath.Join(cacheDir, "master", "deployment-apps-v1.json")
+	if err := os.MkdirAll(filepath.Dir(cached), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cached, []byte(deploymentSchema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * masterCacheTTL)
+	if err := os.Chtimes(cached, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "apiVersion: apps/v1\nkind: Deployment\nspec:\n  replicas: 3\n"
+	if _, err := NewSchemas("master", server.URL+"/{version}/{file}", cacheDir).Validate(content); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if downloads != 1 {
+		t.Errorf("expected the old schema to be downloaded again, got %d downloads", downloads)
+	}
+
+	server.Close()
+	if err := os.Chtimes(cached, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if report, err := NewSchemas("master", server.URL+"/{version}/{file}", cacheDir).Validate(content); err != nil || !report.Valid() {
+		t.Errorf("expected the cached schema offline, got %+v, %v", report, err)
+	}
+}
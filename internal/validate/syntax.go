@@ -0,0 +1,192 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/moby/buildkit/frontend/dockerfile/command"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+	"gopkg.in/yaml.v3"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// SyntaxError is a parse error with the line it was found on. Line is 0 when
+// the parser does not report one.
+type SyntaxError struct {
+	Format  string
+	Line    int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("invalid %s on line %d: %s", e.Format, e.Line, e.Message)
+	}
+	return fmt.Sprintf("invalid %s: %s", e.Format, e.Message)
+}
+
+// HasSyntaxCheck reports whether content of this kind can be checked
+func HasSyntaxCheck(kind filetype.Kind) bool {
+	return kind.IsYAML() || kind == filetype.JSON || kind == filetype.Terraform || kind == filetype.HCL || kind == filetype.Shell || kind == filetype.Dockerfile
+}
+
+// Syntax checks that content of the given kind parses. Kinds without a
+// syntax check always pass.
+func Syntax(kind filetype.Kind, name, content string) error {
+	trimmed := strings.TrimSpace(content)
+	switch {
+	case kind == filetype.JSON || (kind.IsYAML() && strings.HasPrefix(trimmed, "{")):
+		return JSON(content)
+	case kind.IsYAML():
+		return YAML(content)
+	case kind == filetype.Terraform || kind == filetype.HCL:
+		return HCL(name, content)
+	case kind == filetype.Shell:
+		return Shell(name, content)
+	case kind == filetype.Dockerfile:
+		return Dockerfile(content)
+	}
+	return nil
+}
+
+var yamlLinePattern = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// YAML parses every document in content
+func YAML(content string) error {
+	decoder := yaml.NewDecoder(strings.NewReader(content))
+	for doc := 1; ; doc++ {
+		var node yaml.Node
+		err := decoder.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			message := err.Error()
+			line := 0
+			if m := yamlLinePattern.FindStringSubmatch(message); m != nil {
+				line, _ = strconv.Atoi(m[1])
+				message = m[2]
+			}
+			return &SyntaxError{Format: "YAML", Line: line, Message: fmt.Sprintf("%s (document %d)", message, doc)}
+		}
+	}
+}
+
+// JSON parses content as a single JSON value
+func JSON(content string) error {
+	var value interface{}
+	err := json.Unmarshal([]byte(content), &value)
+	if err == nil {
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line := bytes.Count([]byte(content[:syntaxErr.Offset]), []byte("\n")) + 1
+		return &SyntaxError{Format: "JSON", Line: line, Message: syntaxErr.Error()}
+	}
+	return &SyntaxError{Format: "JSON", Message: err.Error()}
+}
+
+// HCL parses content with the HCL native syntax used by Terraform
+func HCL(name, content string) error {
+	_, diags := hclsyntax.ParseConfig([]byte(content), name, hcl.Pos{Line: 1, Column: 1})
+	for _, diag := range diags {
+		if diag.Severity != hcl.DiagError {
+			continue
+		}
+		line := 0
+		if diag.Subject != nil {
+			line = diag.Subject.Start.Line
+		}
+		message := diag.Summary
+		if diag.Detail != "" {
+			message += ": " + diag.Detail
+		}
+		return &SyntaxError{Format: "HCL", Line: line, Message: message}
+	}
+	return nil
+}
+
+// Shell parses content as a bash script, like bash -n
+func Shell(name, content string) error {
+	_, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(strings.NewReader(content), name)
+	if err == nil {
+		return nil
+	}
+
+	var parseErr syntax.ParseError
+	if errors.As(err, &parseErr) {
+		return &SyntaxError{Format: "shell script", Line: int(parseErr.Pos.Line()), Message: parseErr.Text}
+	}
+	return &SyntaxError{Format: "shell script", Message: err.Error()}
+}
+
+// Dockerfile parses content with the BuildKit Dockerfile parser and checks
+// every instruction is known and the first one is FROM or ARG
+func Dockerfile(content string) error {
+	result, err := parser.Parse(strings.NewReader(content))
+	if err != nil {
+		var location *parser.ErrorLocation
+		if errors.As(err, &location) && len(location.Locations) > 0 && len(location.Locations[0]) > 0 {
+			return &SyntaxError{Format: "Dockerfile", Line: location.Locations[0][0].Start.Line, Message: location.Unwrap().Error()}
+		}
+		return &SyntaxError{Format: "Dockerfile", Message: err.Error()}
+	}
+
+	for i, node := range result.AST.Children {
+		instruction := strings.ToLower(node.Value)
+		if _, ok := command.Commands[instruction]; !ok {
+			return &SyntaxError{Format: "Dockerfile", Line: node.StartLine, Message: fmt.Sprintf("unknown instruction %s", strings.ToUpper(node.Value))}
+		}
+		if i == 0 && instruction != command.From && instruction != command.Arg {
+			return &SyntaxError{Format: "Dockerfile", Line: node.StartLine, Message: "the first instruction must be FROM or ARG"}
+		}
+	}
+	return nil
+}
+
+// Fenced code block languages that can be checked
+var blockKinds = map[string]filetype.Kind{
+	"yaml":       filetype.YAML,
+	"yml":        filetype.YAML,
+	"json":       filetype.JSON,
+	"hcl":        filetype.HCL,
+	"terraform":  filetype.Terraform,
+	"tf":         filetype.Terraform,
+	"sh":         filetype.Shell,
+	"bash":       filetype.Shell,
+	"shell":      filetype.Shell,
+	"dockerfile": filetype.Dockerfile,
+}
+
+var fencePattern = regexp.MustCompile("(?s)```([A-Za-z]*)[^\\n]*\\n(.*?)```")
+
+// CodeBlocks checks the fenced code blocks in a Markdown response that are
+// tagged with a language that can be checked. Templated YAML, such as Helm
+// templates, is skipped.
+func CodeBlocks(markdown string) error {
+	problems := []string{}
+	for i, m := range fencePattern.FindAllStringSubmatch(markdown, -1) {
+		kind, ok := blockKinds[strings.ToLower(m[1])]
+		if !ok || (kind.IsYAML() && strings.Contains(m[2], "{{")) {
+			continue
+		}
+		if err := Syntax(kind, fmt.Sprintf("block-%d", i+1), m[2]); err != nil {
+			problems = append(problems, fmt.Sprintf("code block %d (%s): %v", i+1, m[1], err))
+		}
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
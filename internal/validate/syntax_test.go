@@ -0,0 +1,65 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ruanbekker/devops-ai-cli/internal/filetype"
+)
+
+// TestSyntax checks valid and invalid content for each checked format
+func TestSyntax(t *testing.T) {
+	tests := []struct {
+		kind    filetype.Kind
+		content string
+		line    int
+	}{
+		{filetype.Kubernetes, "kind: Service\n---\nkind: Deployment\n", 0},
+		{filetype.Kubernetes, "kind: Service\n---\nkind: Deployment\n  replicas: 2\n", 4},
+		{filetype.JSON, `{"a": 1}`, 0},
+		{filetype.JSON, "{\n  \"a\": 1,\n}", 3},
+		{filetype.Terraform, "resource \"aws_s3_bucket\" \"b\" {\n  bucket = \"logs\"\n}\n", 0},
+		{filetype.Terraform, "resource \"aws_s3_bucket\" \"b\" {\n  bucket = \n}\n", 2},
+		{filetype.Shell, "#!/bin/bash\nif [ -f x ]; then\n  echo ok\nfi\n", 0},
+		{filetype.Shell, "#!/bin/bash\nif [ -f x ]; then\n  echo ok\n", 2},
+		{filetype.Dockerfile, "ARG VERSION=3.20\nFROM alpine:${VERSION}\nRUN <<EOF\napk add curl\nEOF\n", 0},
+		{filetype.Dockerfile, "FROM alpine:3.20\nRUN apk add \\\n  curl\nCOPPY . /app\n", 4},
+		{filetype.Dockerfile, "FROM alpine:3.20\nRUN <<EOF\napk add curl\n", 2},
+		{filetype.Python, "def broken(:\n", 0},
+	}
+
+	for _, tt := range tests {
+		err := Syntax(tt.kind, "test", tt.content)
+		if tt.line == 0 {
+			if err != nil {
+				t.Errorf("expected %s content to be valid, got %v", tt.kind, err)
+			}
+			continue
+		}
+
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("expected a syntax error for %s content, got %v", tt.kind, err)
+			continue
+		}
+		if syntaxErr.Line != tt.line {
+			t.Errorf("expected %s error on line %d, got %v", tt.kind, tt.line, syntaxErr)
+		}
+	}
+}
+
+// TestCodeBlocks checks code blocks in a Markdown response
+func TestCodeBlocks(t *testing.T) {
+	markdown := "Use this:\n\n```yaml\nresources:\n  limits:\n    cpu: 500m\n```\n\n" +
+		"And run:\n\n```bash\nkubectl apply -f deploy.yaml\n```\n\n" +
+		"```yaml\nimage: {{ .Values.image }}\n```\n"
+	if err := CodeBlocks(markdown); err != nil {
+		t.Errorf("expected valid code blocks, got %v", err)
+	}
+
+	err := CodeBlocks(markdown + "\n```json\n{\"a\": }\n```\n")
+	if err == nil || !strings.Contains(err.Error(), "code block 4 (json)") {
+		t.Errorf("expected an error for the JSON block, got %v", err)
+	}
+}